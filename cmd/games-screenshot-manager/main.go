@@ -1,9 +1,11 @@
 package main
 
 import (
+	"os"
+
 	"github.com/fmartingr/games-screenshot-manager/internal/cli"
 )
 
 func main() {
-	cli.Start()
+	os.Exit(cli.Start())
 }
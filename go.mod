@@ -4,12 +4,16 @@ go 1.19
 
 require (
 	github.com/barasher/go-exiftool v1.8.0
+	github.com/cespare/xxhash/v2 v2.2.0
 	github.com/cozy/goexif2 v1.2.0
 	github.com/gosimple/slug v1.13.1
 	github.com/sirupsen/logrus v1.9.0
+	go.etcd.io/bbolt v1.3.7
+	lukechampine.com/blake3 v1.2.1
 )
 
 require (
 	github.com/gosimple/unidecode v1.0.1 // indirect
+	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
 	golang.org/x/sys v0.5.0 // indirect
 )
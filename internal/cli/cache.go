@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+	"github.com/fmartingr/games-screenshot-manager/pkg/registry"
+	"github.com/sirupsen/logrus"
+)
+
+const cacheUsage = `Usage: gsm cache <command> [options]
+
+Commands:
+  list                       List the cached keys
+  clear [-provider X]        Remove the cached values, for all providers or one
+                             (use -provider artwork for the covers)
+  info [-provider X] <key>   Show the details of a cached value, by its key as
+                             shown by list (like steam/steam-applist)
+  warm [-provider X]         Fill the cache in advance, for all providers or one
+
+Options:
+  -cache-backend file|bolt   Backend used to store the cache (default file)
+  -http-*, -user-agent       HTTP options, as in the sync command
+  -log-level                 Log level
+`
+
+// formatBytes returns a human readable size.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// walkCache calls fn for each key in the cache and its namespaces, with the
+// key prefixed by the namespaces it's in.
+func walkCache(cache models.Cache, prefix string, fn func(cache models.Cache, key, fullKey string) error) error {
+	keys, err := cache.Keys()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := fn(cache, key, path.Join(prefix, key)); err != nil {
+			return err
+		}
+	}
+
+	namespaces, err := cache.Namespaces()
+	if err != nil {
+		return err
+	}
+	for _, namespace := range namespaces {
+		if err := walkCache(cache.Namespace(namespace), path.Join(prefix, namespace), fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func cacheList(cache models.Cache, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSIZE\tSTORED")
+
+	var total int64
+	err := walkCache(cache, "", func(cache models.Cache, key, fullKey string) error {
+		size, err := cache.Size(key)
+		if err != nil {
+			return err
+		}
+		total += size
+
+		stored := "-"
+		if metadata, err := cache.GetMetadata(key); err == nil {
+			stored = metadata.StoredAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", fullKey, formatBytes(size), stored)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "TOTAL\t%s\t\n", formatBytes(total))
+	return w.Flush()
+}
+
+func cacheClear(cache models.Cache, out io.Writer) error {
+	removed := 0
+	err := walkCache(cache, "", func(cache models.Cache, key, fullKey string) error {
+		removed++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := cache.Clear(); err != nil {
+		return fmt.Errorf("error clearing cache: %s", err)
+	}
+	fmt.Fprintf(out, "Removed %d keys\n", removed)
+	return nil
+}
+
+// resolveCacheKey returns the cache holding a key as shown by cacheList,
+// prefixed by the namespaces it's in, along with the key in that cache.
+func resolveCacheKey(cache models.Cache, fullKey string) (models.Cache, string) {
+	if _, err := cache.Size(fullKey); err == nil {
+		return cache, fullKey
+	}
+	if namespace, key, found := strings.Cut(fullKey, "/"); found {
+		return resolveCacheKey(cache.Namespace(namespace), key)
+	}
+	return cache, fullKey
+}
+
+func cacheInfo(cache models.Cache, fullKey string, out io.Writer) error {
+	cache, key := resolveCacheKey(cache, fullKey)
+	size, err := cache.Size(key)
+	if err != nil {
+		return err
+	}
+	metadata, err := cache.GetMetadata(key)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Key:\t%s\n", fullKey)
+	fmt.Fprintf(w, "Size:\t%s (%d bytes)\n", formatBytes(size), size)
+	fmt.Fprintf(w, "Stored:\t%s\n", metadata.StoredAt.Format(time.RFC3339))
+	if metadata.SourceURL != "" {
+		fmt.Fprintf(w, "Source URL:\t%s\n", metadata.SourceURL)
+	}
+	if metadata.ETag != "" {
+		fmt.Fprintf(w, "ETag:\t%s\n", metadata.ETag)
+	}
+	if metadata.LastModified != "" {
+		fmt.Fprintf(w, "Last-Modified:\t%s\n", metadata.LastModified)
+	}
+	return w.Flush()
+}
+
+func cacheWarm(ctx context.Context, logger *logrus.Logger, registry *registry.ProviderRegistry, providerNames []string) error {
+	var failed bool
+	for _, providerName := range providerNames {
+		provider, err := registry.Get(providerName)
+		if err != nil {
+			return configError(fmt.Errorf("provider %s not found", providerName))
+		}
+
+		warmer, ok := provider.(models.CacheWarmer)
+		if !ok {
+			logger.Debugf("Provider %s doesn't use the cache", providerName)
+			continue
+		}
+
+		logger.Infof("Warming cache for %s", providerName)
+		if err := warmer.WarmCache(ctx); err != nil {
+			logger.Errorf("Error warming cache for %s: %s", providerName, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		return providerError(errors.New("couldn't warm the cache for all providers"))
+	}
+	return nil
+}
+
+// runCacheCommand handles the `cache` subcommands.
+func runCacheCommand(ctx context.Context, logger *logrus.Logger, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, cacheUsage)
+		return configError(errors.New("missing cache command"))
+	}
+
+	flagSet := flag.NewFlagSet("gsm cache "+args[0], flag.ExitOnError)
+	flagSet.Usage = func() { fmt.Fprint(os.Stderr, cacheUsage) }
+	env := newEnvironment(logger)
+	env.flags(flagSet)
+	providerName := flagSet.String("provider", "", "Limit the command to the cache of a provider")
+	if err := flagSet.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if err := env.setup(); err != nil {
+		return err
+	}
+	if err := env.openRegistry(); err != nil {
+		return err
+	}
+	defer env.close()
+
+	target := env.cache
+	if *providerName != "" {
+		if _, err := env.registry.Get(*providerName); err != nil && *providerName != processor.ArtworkNamespace {
+			return configError(fmt.Errorf("provider %s not found", *providerName))
+		}
+		target = env.cache.Namespace(*providerName)
+	}
+
+	switch args[0] {
+	case "list":
+		return cacheList(target, os.Stdout)
+	case "clear":
+		return cacheClear(target, os.Stdout)
+	case "info":
+		if flagSet.NArg() != 1 {
+			fmt.Fprint(os.Stderr, cacheUsage)
+			return configError(errors.New("missing cache key"))
+		}
+		return cacheInfo(target, flagSet.Arg(0), os.Stdout)
+	case "warm":
+		providerNames := env.registry.Names()
+		if *providerName != "" {
+			// Covers are only downloaded while syncing
+			if _, err := env.registry.Get(*providerName); err != nil {
+				return configError(fmt.Errorf("the %s cache can't be warmed, only providers can", *providerName))
+			}
+			providerNames = []string{*providerName}
+		}
+		return cacheWarm(ctx, logger, env.registry, providerNames)
+	default:
+		fmt.Fprint(os.Stderr, cacheUsage)
+		return configError(fmt.Errorf("unknown cache command: %s", args[0]))
+	}
+}
@@ -2,19 +2,12 @@ package cli
 
 import (
 	"context"
-	"flag"
+	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
-	"github.com/fmartingr/games-screenshot-manager/internal/models"
-	"github.com/fmartingr/games-screenshot-manager/pkg/cache"
-	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
-	"github.com/fmartingr/games-screenshot-manager/pkg/providers/minecraft"
-	"github.com/fmartingr/games-screenshot-manager/pkg/providers/playstation4"
-	"github.com/fmartingr/games-screenshot-manager/pkg/providers/playstation5"
-	"github.com/fmartingr/games-screenshot-manager/pkg/providers/retroarch"
-	"github.com/fmartingr/games-screenshot-manager/pkg/providers/steam"
-	"github.com/fmartingr/games-screenshot-manager/pkg/providers/xbox_game_bar"
-	"github.com/fmartingr/games-screenshot-manager/pkg/registry"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,70 +17,79 @@ const defaultInputPath string = ""
 const defaultProvider string = "steam"
 const defaultDryRun bool = false
 const defaultDownloadCovers bool = false
+const defaultMergeVariants bool = false
+const defaultCoverSources string = "local,provider,steam,libretro,steamgriddb"
+const defaultCacheBackend string = "file"
+
+const usage = `Usage: gsm [command] [options]
+
+Commands:
+  sync                       Import the screenshots of a provider into the output path (default)
+  list games|screenshots     List what a provider finds, without copying anything
+  providers                  List the providers and whether their screenshots are found
+  verify                     Check that the screenshots of a provider are in the output path
+  stats                      Show the games and screenshots in the output path
+  cache                      Manage the cache
+
+Use gsm <command> -h to list the options of a command.
+`
+
+// command runs a subcommand with its arguments.
+type command func(ctx context.Context, logger *logrus.Logger, args []string) error
+
+var commands = map[string]command{
+	"sync":      runSync,
+	"list":      runList,
+	"providers": runProviders,
+	"verify":    runVerify,
+	"stats":     runStats,
+	"cache":     runCacheCommand,
+}
 
-func Start() {
+// Start runs the command in the program arguments, returning the exit code.
+func Start() int {
 	logger := logrus.New()
-	flagSet := flag.NewFlagSet("gsm", flag.ExitOnError)
-
-	cache := cache.NewFileCache(logger)
 
-	registry := registry.NewProviderRegistry(logger, cache)
-	registry.Register(minecraft.Name, minecraft.NewMinecraftProvider)
-	registry.Register(playstation4.Name, playstation4.NewPlaystation4Provider)
-	registry.Register(playstation5.Name, playstation5.NewPlaystation5Provider)
-	registry.Register(xbox_game_bar.Name, xbox_game_bar.NewXboxGameGarProvider)
-	registry.Register(steam.Name, steam.NewSteamProvider)
-	registry.Register(retroarch.Name, retroarch.NewRetroArchProvider)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handleSignals(logger, cancel)
 
-	options := models.Options{
-		ProcessBufferSize: 32,
+	// Without a command the options are for sync, as in older versions
+	name, args := "sync", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	flagSet.StringVar(&options.OutputPath, "output-path", defaultOutputPath, "The destination path of the screenshots")
-	flagSet.BoolVar(&options.DownloadCovers, "download-covers", defaultDownloadCovers, "use to enable the download of covers (if the provider supports it)")
-	flagSet.BoolVar(&options.DryRun, "dry-run", defaultDryRun, "Use to disable write actions on filesystem")
-	flagSet.IntVar(&options.WorkersNum, "workers-num", 2, "Number of workers to use to process games")
-
-	var providerName = flagSet.String("provider", defaultProvider, "steam")
-	providerOptions := models.ProviderOptions{}
-	flagSet.StringVar(&providerOptions.InputPath, "input-path", defaultInputPath, "Input path for the provider that requires it")
-
-	loglevelFlag := flagSet.String("log-level", logrus.InfoLevel.String(), "Log level")
-
-	if err := flagSet.Parse(os.Args[1:]); err != nil {
-		logger.Errorf("error parsing args: %s", err)
+	if name == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		return ExitSuccess
 	}
 
-	loglevel, err := logrus.ParseLevel(*loglevelFlag)
-	if err != nil {
-		logger.Warnf("Invalid loglevel %s, using %s instead.", *loglevelFlag, logrus.InfoLevel.String())
-		loglevel = logrus.InfoLevel
+	run, exists := commands[name]
+	if !exists {
+		fmt.Fprint(os.Stderr, usage)
+		logger.Errorf("Unknown command %s", name)
+		return ExitConfigError
 	}
-	logger.SetLevel(loglevel)
 
-	provider, err := registry.Get(*providerName)
-	if err != nil {
-		logger.Errorf("Provider %s not found!", *providerName)
-		return
-	}
-	games, err := provider.FindGames(providerOptions)
+	err := run(ctx, logger, args)
 	if err != nil {
-		logger.Errorf("Error obtaining game list: %s", err)
-		return
+		logger.Error(err)
 	}
+	return exitCode(err)
+}
 
-	if len(games) > 0 {
-		ctx, cancel := context.WithCancel(context.Background())
-		processor := processor.NewProcessor(logger, options)
-		processor.Start(ctx)
+// handleSignals cancels the context on the first interrupt so commands can
+// finish the file in progress and report what was left, and exits right away
+// on the second one.
+func handleSignals(logger *logrus.Logger, cancel context.CancelFunc) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 
-		for _, g := range games {
-			processor.Process(g)
-		}
+	<-signals
+	logger.Warn("Interrupted, stopping. Press Ctrl+C again to exit immediately.")
+	cancel()
 
-		processor.Wait()
-		cancel()
-	} else {
-		logger.Info("No games found.")
-	}
+	<-signals
+	os.Exit(ExitInterrupted)
 }
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/cache"
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/fmartingr/games-screenshot-manager/pkg/identity"
+	"github.com/fmartingr/games-screenshot-manager/pkg/platforms"
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/minecraft"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/playstation4"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/playstation5"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/retroarch"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/steam"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/xbox_game_bar"
+	"github.com/fmartingr/games-screenshot-manager/pkg/registry"
+	"github.com/sirupsen/logrus"
+)
+
+// environment holds the configuration and plumbing shared by the commands:
+// logging, HTTP client, cache, provider registry and game naming.
+type environment struct {
+	logger *logrus.Logger
+
+	logLevel     string
+	cacheBackend string
+	httpOptions  *helpers.HTTPOptions
+
+	providerName    string
+	providerOptions models.ProviderOptions
+	aliasesFile     string
+	platformsFile   string
+
+	cache     models.Cache
+	registry  *registry.ProviderRegistry
+	platforms *platforms.Normalizer
+	resolver  *identity.Resolver
+}
+
+// flags registers the flags common to all commands.
+func (e *environment) flags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&e.cacheBackend, "cache-backend", defaultCacheBackend, "Backend used to store the cache (file or bolt)")
+	e.httpOptions = httpFlags(flagSet)
+	flagSet.StringVar(&e.logLevel, "log-level", logrus.InfoLevel.String(), "Log level")
+}
+
+// providerFlags registers the flags used by commands reading games from a
+// provider.
+func (e *environment) providerFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&e.providerName, "provider", defaultProvider, "Provider to read the games from")
+	e.inputPathFlag(flagSet)
+	flagSet.StringVar(&e.aliasesFile, "aliases-file", defaultAliasesPath(), "JSON file mapping game names or <provider>:<game id> to the names to use instead")
+	flagSet.StringVar(&e.platformsFile, "platforms-file", "", "JSON file mapping platform names to the ones to use instead")
+}
+
+func (e *environment) inputPathFlag(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&e.providerOptions.InputPath, "input-path", defaultInputPath, "Input path for the provider that requires it")
+}
+
+// setup applies the common flags once parsed.
+func (e *environment) setup() error {
+	loglevel, err := logrus.ParseLevel(e.logLevel)
+	if err != nil {
+		e.logger.Warnf("Invalid loglevel %s, using %s instead.", e.logLevel, logrus.InfoLevel.String())
+		loglevel = logrus.InfoLevel
+	}
+	e.logger.SetLevel(loglevel)
+
+	if err := setupHTTPClient(e.httpOptions); err != nil {
+		return configError(fmt.Errorf("error configuring HTTP client: %s", err))
+	}
+	return nil
+}
+
+// openRegistry opens the cache and registers the providers.
+func (e *environment) openRegistry() error {
+	cache, err := newCache(e.logger, e.cacheBackend)
+	if err != nil {
+		return fmt.Errorf("error opening cache: %w", err)
+	}
+	e.cache = cache
+	e.registry = newProviderRegistry(e.logger, cache)
+	return nil
+}
+
+// loadNames loads the platforms and aliases files.
+func (e *environment) loadNames() error {
+	if e.platformsFile != "" {
+		if err := e.platforms.LoadFile(e.platformsFile); err != nil {
+			return configError(fmt.Errorf("error loading platforms file: %s", err))
+		}
+	}
+
+	if e.aliasesFile != "" {
+		// The default aliases file only exists once names have been saved
+		if err := e.resolver.LoadFile(e.aliasesFile); err != nil && !(errors.Is(err, os.ErrNotExist) && e.aliasesFile == defaultAliasesPath()) {
+			return configError(fmt.Errorf("error loading aliases file: %s", err))
+		}
+		for _, alias := range e.resolver.UnknownAliases(e.registry.Names()) {
+			e.logger.Warnf("Alias %s doesn't start with a known provider, it will only match game names", alias)
+		}
+	}
+	return nil
+}
+
+// open runs all the setup needed to find games.
+func (e *environment) open() error {
+	if err := e.setup(); err != nil {
+		return err
+	}
+	if err := e.openRegistry(); err != nil {
+		return err
+	}
+	return e.loadNames()
+}
+
+func (e *environment) close() {
+	if e.cache != nil {
+		closeCache(e.logger, e.cache)
+	}
+}
+
+// findGames returns the games found by the selected provider, with their
+// platforms normalized and names resolved.
+func (e *environment) findGames(ctx context.Context) ([]*models.Game, error) {
+	provider, err := e.registry.Get(e.providerName)
+	if err != nil {
+		return nil, configError(fmt.Errorf("provider %s not found", e.providerName))
+	}
+
+	games, err := provider.FindGames(ctx, e.providerOptions)
+	if err != nil {
+		return nil, providerError(fmt.Errorf("error obtaining game list: %s", err))
+	}
+
+	e.platforms.NormalizeGames(games)
+	e.resolver.ResolveGames(games)
+	return games, nil
+}
+
+func newEnvironment(logger *logrus.Logger) *environment {
+	return &environment{
+		logger:    logger,
+		platforms: platforms.NewNormalizer(),
+		resolver:  identity.NewResolver(),
+	}
+}
+
+// outputFlags registers the flags setting where and how games are stored.
+func outputFlags(flagSet *flag.FlagSet, options *models.Options) {
+	flagSet.StringVar(&options.OutputPath, "output-path", defaultOutputPath, "The destination path of the screenshots")
+	flagSet.StringVar(&options.GroupBy, "group-by", processor.GroupByPlatform, "Group the output by platform (<platform>/<game>) or by game (<game>/<platform>)")
+	flagSet.BoolVar(&options.MergeVariants, "merge-variants", defaultMergeVariants, "Store all variants of a game (editions, sources, launcher instances) in the same folder")
+	flagSet.StringVar(&options.NearDuplicates, "near-duplicates", processor.NearDuplicatesKeepAll, "What to do with screenshots of a game that look the same and were taken at about the same time: keep-all, keep-best (only the highest quality one) or move (the rest to a "+processor.DuplicatesFolder+" folder)")
+	flagSet.DurationVar(&options.NearDuplicatesWindow, "near-duplicates-window", processor.DefaultNearDuplicatesWindow, "Maximum time between screenshots to consider them near duplicates")
+	flagSet.StringVar(&options.HashAlgorithm, "hash", hashing.Default, "Algorithm used to compare screenshots: "+strings.Join(hashing.Algorithms(), ", "))
+}
+
+func validateOutputOptions(options models.Options) error {
+	if options.GroupBy != processor.GroupByPlatform && options.GroupBy != processor.GroupByGame {
+		return configError(fmt.Errorf("invalid group by %s, use %s or %s", options.GroupBy, processor.GroupByPlatform, processor.GroupByGame))
+	}
+	switch options.NearDuplicates {
+	case processor.NearDuplicatesKeepAll, processor.NearDuplicatesKeepBest, processor.NearDuplicatesMove:
+	default:
+		return configError(fmt.Errorf("invalid near duplicates policy %s, use %s, %s or %s", options.NearDuplicates, processor.NearDuplicatesKeepAll, processor.NearDuplicatesKeepBest, processor.NearDuplicatesMove))
+	}
+	if _, err := hashing.NewHasher(options.HashAlgorithm); err != nil {
+		return configError(err)
+	}
+	return nil
+}
+
+// newCache returns the cache for the provided backend.
+func newCache(logger *logrus.Logger, backend string) (models.Cache, error) {
+	switch backend {
+	case "file":
+		return cache.NewFileCache(logger), nil
+	case "bolt":
+		return cache.NewBoltCache(logger)
+	default:
+		return nil, configError(fmt.Errorf("unknown cache backend %s, use file or bolt", backend))
+	}
+}
+
+// closeCache closes the cache if its backend needs it.
+func closeCache(logger *logrus.Logger, cache models.Cache) {
+	if closer, ok := cache.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Errorf("Error closing cache: %s", err)
+		}
+	}
+}
+
+// httpFlags registers the flags configuring the HTTP client, returning the
+// options they are parsed into.
+func httpFlags(flagSet *flag.FlagSet) *helpers.HTTPOptions {
+	options := helpers.DefaultHTTPOptions
+	flagSet.DurationVar(&options.Timeout, "http-timeout", options.Timeout, "Timeout for each HTTP request")
+	flagSet.IntVar(&options.Retries, "http-retries", options.Retries, "Number of retries for HTTP requests failing with a server error or rate limit")
+	flagSet.StringVar(&options.Proxy, "http-proxy", "", "Proxy URL for HTTP requests (uses HTTP_PROXY/HTTPS_PROXY if empty)")
+	flagSet.StringVar(&options.UserAgent, "user-agent", options.UserAgent, "User-Agent for HTTP requests")
+	flagSet.BoolVar(&options.Offline, "offline", false, "Don't make any network request, using only cached data")
+	return &options
+}
+
+// setupHTTPClient replaces the HTTP client shared by the application.
+func setupHTTPClient(options *helpers.HTTPOptions) error {
+	client, err := helpers.NewHTTPClient(*options)
+	if err != nil {
+		return err
+	}
+	helpers.SetHTTPClient(client)
+	return nil
+}
+
+func newProviderRegistry(logger *logrus.Logger, cache models.Cache) *registry.ProviderRegistry {
+	registry := registry.NewProviderRegistry(logger, cache)
+	registry.Register(minecraft.Name, minecraft.NewMinecraftProvider)
+	registry.Register(playstation4.Name, playstation4.NewPlaystation4Provider)
+	registry.Register(playstation5.Name, playstation5.NewPlaystation5Provider)
+	registry.Register(xbox_game_bar.Name, xbox_game_bar.NewXboxGameGarProvider)
+	registry.Register(steam.Name, steam.NewSteamProvider)
+	registry.Register(retroarch.Name, retroarch.NewRetroArchProvider)
+	return registry
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+	"github.com/sirupsen/logrus"
+)
+
+// logEvents returns an observer logging the events of a processor. In dry
+// runs the screenshots that would be copied are logged as cp commands.
+func logEvents(logger *logrus.Logger, options models.Options) processor.Observer {
+	outputPath := helpers.ExpandUser(options.OutputPath)
+
+	return processor.ObserverFunc(func(event processor.Event) {
+		switch event.Type {
+		case processor.EventGameStarted:
+			logger.Debugf("Processing game %s from %s", event.Game.Name, event.Game.Provider)
+
+		case processor.EventScreenshotCopied:
+			destination := strings.Replace(event.Screenshot.Destination, outputPath, "", 1)
+			if options.DryRun {
+				logger.Infof("cp %s %s", filepath.Base(event.Screenshot.Source), destination)
+			} else {
+				logger.Debugf("Copied %s to %s", event.Screenshot.Source, destination)
+			}
+
+		case processor.EventConflict:
+			logger.Warnf("Found different screenshot with equal timestamp for game %s from %s on %s", event.Game.Name, event.Game.Provider, event.Screenshot.Source)
+
+		case processor.EventCoverDownloaded:
+			logger.Debugf("Downloaded cover %s for game %s from %s", event.Cover, event.Game.Name, event.Game.Provider)
+
+		case processor.EventError:
+			if event.Screenshot != nil {
+				logger.WithFields(logrus.Fields{
+					"src":  event.Screenshot.Source,
+					"dest": event.Screenshot.Destination,
+				}).Errorf("%s (game %s from %s)", event.Err, event.Game.Name, event.Game.Provider)
+				return
+			}
+			logger.Error(event.Err)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package cli
+
+import "errors"
+
+// Exit codes, so scripts can tell what went wrong. Invalid flags exit with
+// ExitConfigError too.
+const (
+	ExitSuccess         = 0
+	ExitFailure         = 1
+	ExitConfigError     = 2
+	ExitPartialFailure  = 3
+	ExitProviderFailure = 4
+	ExitInterrupted     = 130
+)
+
+// exitError is an error with the exit code it should end the program with.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// configError marks errors caused by the flags or configuration files.
+func configError(err error) error {
+	return &exitError{code: ExitConfigError, err: err}
+}
+
+// providerError marks errors of a provider finding games.
+func providerError(err error) error {
+	return &exitError{code: ExitProviderFailure, err: err}
+}
+
+// partialFailureError marks runs where some of the work failed.
+func partialFailureError(err error) error {
+	return &exitError{code: ExitPartialFailure, err: err}
+}
+
+// interruptedError marks runs stopped by a signal.
+func interruptedError(err error) error {
+	return &exitError{code: ExitInterrupted, err: err}
+}
+
+// exitCode returns the exit code for the error returned by a command.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return ExitFailure
+}
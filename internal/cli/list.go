@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const listUsage = `Usage: gsm list games|screenshots [options]
+
+Lists what a provider finds, without copying anything.
+`
+
+// displayName returns the name of the game with its variant, or a
+// placeholder if it has no name.
+func displayName(game *models.Game) string {
+	if game.Name == "" {
+		return "(unnamed)"
+	}
+	return game.FolderName(false)
+}
+
+func listGames(games []*models.Game, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPLATFORM\tPROVIDER\tID\tSCREENSHOTS")
+	for _, game := range games {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", displayName(game), game.Platform, game.Provider, game.ID, len(game.Screenshots))
+	}
+	return w.Flush()
+}
+
+func listScreenshots(games []*models.Game, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GAME\tPLATFORM\tPATH\tDESTINATION")
+	for _, game := range games {
+		for _, screenshot := range game.Screenshots {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", displayName(game), game.Platform, screenshot.Path, screenshot.GetDestinationName())
+		}
+	}
+	return w.Flush()
+}
+
+// runList handles the `list` command.
+func runList(ctx context.Context, logger *logrus.Logger, args []string) error {
+	if len(args) == 0 || (args[0] != "games" && args[0] != "screenshots") {
+		fmt.Fprint(os.Stderr, listUsage)
+		return configError(errors.New("list games or screenshots"))
+	}
+
+	flagSet := flag.NewFlagSet("gsm list "+args[0], flag.ExitOnError)
+	env := newEnvironment(logger)
+	env.flags(flagSet)
+	env.providerFlags(flagSet)
+	if err := flagSet.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if err := env.open(); err != nil {
+		return err
+	}
+	defer env.close()
+
+	games, err := env.findGames(ctx)
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "games" {
+		return listGames(games, os.Stdout)
+	}
+	return listScreenshots(games, os.Stdout)
+}
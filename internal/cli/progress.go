@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+	"github.com/sirupsen/logrus"
+)
+
+// Progress modes: a bar when writing to a terminal, periodic log lines
+// otherwise.
+const (
+	progressAuto = "auto"
+	progressBar  = "bar"
+	progressLog  = "log"
+	progressNone = "none"
+)
+
+const (
+	progressBarInterval = 200 * time.Millisecond
+	progressLogInterval = 10 * time.Second
+	progressBarWidth    = 30
+	progressGameWidth   = 30
+)
+
+// progressReporter renders the progress of a run to stderr until stopped.
+type progressReporter struct {
+	logger   *logrus.Logger
+	progress *processor.Progress
+	mode     string
+
+	// mu serializes drawing the bar and writing log lines
+	mu        sync.Mutex
+	logOutput io.Writer
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// isTerminal checks if the file is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// startProgress starts reporting the progress in the provided mode.
+func startProgress(logger *logrus.Logger, progress *processor.Progress, mode string) *progressReporter {
+	if mode == progressAuto {
+		mode = progressLog
+		if isTerminal(os.Stderr) {
+			mode = progressBar
+		}
+	}
+
+	r := &progressReporter{
+		logger:   logger,
+		progress: progress,
+		mode:     mode,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	interval := progressLogInterval
+	switch mode {
+	case progressNone:
+		close(r.stopped)
+		return r
+	case progressBar:
+		interval = progressBarInterval
+		// Log lines clear the bar, which is drawn again on the next tick
+		r.logOutput = logger.Out
+		logger.SetOutput(&barLogWriter{reporter: r})
+	}
+
+	go r.run(interval)
+	return r
+}
+
+func (r *progressReporter) run(interval time.Duration) {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.report()
+		}
+	}
+}
+
+func (r *progressReporter) report() {
+	status := r.progress.Status()
+	if r.mode == progressBar {
+		r.mu.Lock()
+		fmt.Fprint(os.Stderr, "\r\033[K"+formatProgressBar(status))
+		r.mu.Unlock()
+		return
+	}
+
+	line := fmt.Sprintf("Progress: %d/%d files, %s/%s (%d%%), %s/s", status.Files, status.FilesTotal, formatBytes(status.Bytes), formatBytes(status.BytesTotal), progressPercent(status), formatBytes(int64(status.Throughput)))
+	if status.ETA > 0 {
+		line += ", ETA " + formatETA(status.ETA)
+	}
+	if status.Game != "" {
+		line += ", copying " + status.Game
+	}
+	r.logger.Info(line)
+}
+
+// stop reports the final progress and stops reporting.
+func (r *progressReporter) stop() {
+	if r.mode == progressNone {
+		return
+	}
+
+	close(r.done)
+	<-r.stopped
+	r.report()
+
+	if r.mode == progressBar {
+		r.mu.Lock()
+		fmt.Fprintln(os.Stderr)
+		r.mu.Unlock()
+		r.logger.SetOutput(r.logOutput)
+	}
+}
+
+// barLogWriter clears the progress bar before writing log lines.
+type barLogWriter struct {
+	reporter *progressReporter
+}
+
+func (w *barLogWriter) Write(p []byte) (int, error) {
+	w.reporter.mu.Lock()
+	defer w.reporter.mu.Unlock()
+
+	fmt.Fprint(w.reporter.logOutput, "\r\033[K")
+	return w.reporter.logOutput.Write(p)
+}
+
+func progressPercent(status processor.ProgressStatus) int {
+	if status.BytesTotal > 0 {
+		return int(status.Bytes * 100 / status.BytesTotal)
+	}
+	if status.FilesTotal > 0 {
+		return status.Files * 100 / status.FilesTotal
+	}
+	return 0
+}
+
+// formatProgressBar returns a single line with a bar and the progress.
+func formatProgressBar(status processor.ProgressStatus) string {
+	percent := progressPercent(status)
+	filled := percent * progressBarWidth / 100
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+
+	eta := "--"
+	if status.ETA > 0 {
+		eta = formatETA(status.ETA)
+	}
+
+	game := status.Game
+	if len([]rune(game)) > progressGameWidth {
+		game = string([]rune(game)[:progressGameWidth-1]) + "…"
+	}
+
+	return fmt.Sprintf("[%s%s] %3d%%  %d/%d files  %s/%s  %s/s  ETA %s  %s",
+		strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), percent,
+		status.Files, status.FilesTotal,
+		formatBytes(status.Bytes), formatBytes(status.BytesTotal),
+		formatBytes(int64(status.Throughput)), eta, game)
+}
+
+// formatETA rounds the duration to seconds.
+func formatETA(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
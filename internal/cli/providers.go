@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/registry"
+	"github.com/sirupsen/logrus"
+)
+
+// providerStatus returns whether the provider screenshots are available, the
+// path they would be read from and the options the provider uses.
+func providerStatus(provider models.Provider, options models.ProviderOptions) (status, path, providerOptions string) {
+	detector, ok := provider.(models.ProviderDetector)
+	if !ok {
+		return "unknown", "-", "-"
+	}
+
+	providerOptions = "-"
+	if info := detector.Info(); info.InputPath != "" {
+		providerOptions = "-input-path: " + info.InputPath
+		if info.InputPathRequired {
+			providerOptions += " (required)"
+		}
+	}
+
+	path, err := detector.Detect(options)
+	switch {
+	case err == nil:
+		return "detected", path, providerOptions
+	case errors.Is(err, models.ErrInputPathRequired):
+		return "needs -input-path", "-", providerOptions
+	default:
+		return "not found", "-", providerOptions
+	}
+}
+
+func listProviders(registry *registry.ProviderRegistry, options models.ProviderOptions, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tPATH\tOPTIONS")
+	for _, name := range registry.Names() {
+		provider, err := registry.Get(name)
+		if err != nil {
+			return err
+		}
+		status, path, providerOptions := providerStatus(provider, options)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, status, path, providerOptions)
+	}
+	return w.Flush()
+}
+
+// runProviders handles the `providers` command.
+func runProviders(ctx context.Context, logger *logrus.Logger, args []string) error {
+	flagSet := flag.NewFlagSet("gsm providers", flag.ExitOnError)
+	env := newEnvironment(logger)
+	env.flags(flagSet)
+	env.inputPathFlag(flagSet)
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if err := env.setup(); err != nil {
+		return err
+	}
+	if err := env.openRegistry(); err != nil {
+		return err
+	}
+	defer env.close()
+
+	return listProviders(env.registry, env.providerOptions, os.Stdout)
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+	"github.com/sirupsen/logrus"
+)
+
+// platformStats are the totals of a platform in the output path.
+type platformStats struct {
+	games       int
+	screenshots int
+	covers      int
+	size        int64
+}
+
+// subdirectories returns the names of the directories in path, sorted.
+func subdirectories(path string) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			result = append(result, entry.Name())
+		}
+	}
+	return result, nil
+}
+
+// addGameStats adds the files in a game folder to the platform stats.
+func addGameStats(stats *platformStats, gamePath string) error {
+	entries, err := os.ReadDir(gamePath)
+	if err != nil {
+		return err
+	}
+
+	stats.games++
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(entry.Name(), "cover") {
+			stats.covers++
+		} else {
+			stats.screenshots++
+		}
+		stats.size += info.Size()
+	}
+	return nil
+}
+
+// outputStats returns the stats of the output path by platform.
+func outputStats(options models.Options) (map[string]*platformStats, error) {
+	outputPath := helpers.ExpandUser(options.OutputPath)
+	result := make(map[string]*platformStats)
+
+	parents, err := subdirectories(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, parent := range parents {
+		children, err := subdirectories(filepath.Join(outputPath, parent))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range children {
+			platform := parent
+			if options.GroupBy == processor.GroupByGame {
+				platform = child
+			}
+			if result[platform] == nil {
+				result[platform] = &platformStats{}
+			}
+
+			if err := addGameStats(result[platform], filepath.Join(outputPath, parent, child)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func printStats(stats map[string]*platformStats, out io.Writer) error {
+	platforms := make([]string, 0, len(stats))
+	for platform := range stats {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	var total platformStats
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLATFORM\tGAMES\tSCREENSHOTS\tCOVERS\tSIZE")
+	for _, platform := range platforms {
+		s := stats[platform]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", platform, s.games, s.screenshots, s.covers, formatBytes(s.size))
+		total.games += s.games
+		total.screenshots += s.screenshots
+		total.covers += s.covers
+		total.size += s.size
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t%s\n", total.games, total.screenshots, total.covers, formatBytes(total.size))
+	return w.Flush()
+}
+
+// runStats handles the `stats` command.
+func runStats(ctx context.Context, logger *logrus.Logger, args []string) error {
+	flagSet := flag.NewFlagSet("gsm stats", flag.ExitOnError)
+	env := newEnvironment(logger)
+	env.flags(flagSet)
+	options := models.Options{}
+	outputFlags(flagSet, &options)
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateOutputOptions(options); err != nil {
+		return err
+	}
+	if err := env.setup(); err != nil {
+		return err
+	}
+
+	stats, err := outputStats(options)
+	if err != nil {
+		return fmt.Errorf("error reading output path: %s", err)
+	}
+	return printStats(stats, os.Stdout)
+}
@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/artwork"
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/fmartingr/games-screenshot-manager/pkg/identity"
+	"github.com/fmartingr/games-screenshot-manager/pkg/library"
+	"github.com/fmartingr/games-screenshot-manager/pkg/platforms"
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+	"github.com/sirupsen/logrus"
+)
+
+// Output formats for the results of the sync command.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+func printJSON(out io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// runSync imports the screenshots found by a provider into the output path.
+func runSync(ctx context.Context, logger *logrus.Logger, args []string) error {
+	flagSet := flag.NewFlagSet("gsm sync", flag.ExitOnError)
+	env := newEnvironment(logger)
+	env.flags(flagSet)
+	env.providerFlags(flagSet)
+
+	options := models.Options{
+		ProcessBufferSize: 32,
+	}
+
+	outputFlags(flagSet, &options)
+	flagSet.BoolVar(&options.DownloadCovers, "download-covers", defaultDownloadCovers, "use to enable the download of covers (if the provider supports it)")
+	coverVariants := flagSet.String("cover-variants", "", "Comma separated list of cover variants to download (boxart, title, snap, library, header, capsule, grid, local). Downloads the first available one if empty")
+	coverSources := flagSet.String("cover-sources", defaultCoverSources, "Comma separated list of sources to look for covers in, in order")
+	coversPath := flagSet.String("covers-path", "", "Directory with user supplied covers, named <platform>/<game name>.png or <game name>.png")
+	steamGridDBURL := flagSet.String("steamgriddb-url", artwork.DefaultSteamGridDBURL, "URL of the SteamGridDB compatible API")
+	steamGridDBAPIKey := flagSet.String("steamgriddb-api-key", "", "API key for the SteamGridDB compatible API (required to use it as a cover source)")
+	flagSet.BoolVar(&options.RefreshCovers, "refresh-covers", false, "Download covers again, replacing existing ones and ignoring the cache")
+	flagSet.BoolVar(&options.DryRun, "dry-run", defaultDryRun, "Use to disable write actions on filesystem")
+	flagSet.IntVar(&options.WorkersNum, "workers-num", 2, "Number of screenshots copied at the same time (and of games prepared)")
+	fileMode := flagSet.String("file-mode", fmt.Sprintf("%#o", helpers.DefaultFileMode), "Mode (octal) of the copied screenshots and covers")
+	dirMode := flagSet.String("dir-mode", fmt.Sprintf("%#o", helpers.DefaultDirMode), "Mode (octal) of the created folders")
+	flagSet.BoolVar(&options.PreserveTimes, "preserve-times", true, "Keep the modification and access times of the screenshots on their copies")
+	owner := flagSet.String("owner", "", "Owner of the copied files and created folders, as user[:group] names or IDs (leave empty to keep the current user)")
+	useIndex := flagSet.Bool("index", true, "Keep an index of the output path ("+library.IndexFileName+") to skip screenshots already stored under another name")
+	ioLimit := flagSet.String("io-limit", "", "Limit the copy throughput in bytes per second (e.g. 500K, 20M), for slow destinations")
+	interactive := flagSet.Bool("interactive", false, "Ask for the names of the games that couldn't be named, storing them in the aliases file")
+	progressMode := flagSet.String("progress", progressAuto, "How to report the progress: auto (a bar on terminals, log lines otherwise), bar, log or none")
+	outputFormat := flagSet.String("output", outputText, "Output format for the results: text (log lines) or json (a report printed to stdout)")
+
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateOutputOptions(options); err != nil {
+		return err
+	}
+
+	if *outputFormat != outputText && *outputFormat != outputJSON {
+		return configError(fmt.Errorf("invalid output %s, use %s or %s", *outputFormat, outputText, outputJSON))
+	}
+	switch *progressMode {
+	case progressAuto, progressBar, progressLog, progressNone:
+	default:
+		return configError(fmt.Errorf("invalid progress %s, use %s, %s, %s or %s", *progressMode, progressAuto, progressBar, progressLog, progressNone))
+	}
+	if *outputFormat == outputJSON && *interactive {
+		return configError(errors.New("interactive mode can't be used with json output"))
+	}
+
+	if options.WorkersNum < 1 {
+		return configError(fmt.Errorf("invalid workers number %d", options.WorkersNum))
+	}
+
+	var err error
+	if options.FileMode, err = parseMode(*fileMode); err != nil {
+		return configError(fmt.Errorf("invalid file mode %s: %s", *fileMode, err))
+	}
+	if options.DirMode, err = parseMode(*dirMode); err != nil {
+		return configError(fmt.Errorf("invalid dir mode %s: %s", *dirMode, err))
+	}
+	if *owner != "" {
+		if options.Owner, err = parseOwner(*owner); err != nil {
+			return configError(fmt.Errorf("invalid owner %s: %s", *owner, err))
+		}
+	}
+	if *ioLimit != "" {
+		limit, err := parseBytes(*ioLimit)
+		if err != nil {
+			return configError(fmt.Errorf("invalid io limit %s: %s", *ioLimit, err))
+		}
+		options.IOLimit = limit
+	}
+
+	if *coverVariants != "" {
+		options.CoverVariants = strings.Split(*coverVariants, ",")
+	}
+
+	if err := env.open(); err != nil {
+		return err
+	}
+	defer env.close()
+
+	games, err := env.findGames(ctx)
+	if err != nil {
+		return err
+	}
+
+	if unresolved := identity.Unresolved(games); len(unresolved) > 0 {
+		if *interactive {
+			aliases := promptNames(unresolved, os.Stdin, os.Stdout)
+			if len(aliases) > 0 && env.aliasesFile != "" {
+				if err := identity.SaveAliases(env.aliasesFile, aliases); err != nil {
+					logger.Errorf("Error saving names: %s", err)
+				}
+			}
+			unresolved = identity.Unresolved(unresolved)
+		}
+		reportUnresolved(logger, unresolved, env.aliasesFile)
+	}
+
+	if len(games) == 0 {
+		logger.Info("No games found.")
+		if *outputFormat == outputJSON {
+			return printJSON(os.Stdout, processor.NewReport(options.DryRun, nil))
+		}
+		return nil
+	}
+
+	artworkResolver := newArtworkResolver(logger, env.platforms, strings.Split(*coverSources, ","), *coversPath, *steamGridDBURL, *steamGridDBAPIKey)
+	p, err := processor.NewProcessor(logger, env.cache, options, artworkResolver)
+	if err != nil {
+		return configError(err)
+	}
+	var index *library.Index
+	if *useIndex {
+		hasher, err := hashing.NewHasher(options.HashAlgorithm)
+		if err != nil {
+			return configError(err)
+		}
+		if index, err = library.OpenIndex(logger, options.OutputPath, hasher); err != nil {
+			return fmt.Errorf("error opening library index: %s", err)
+		}
+		p.UseIndex(index)
+	}
+
+	results := processor.NewCollector()
+	p.Subscribe(results)
+	p.Subscribe(logEvents(logger, options))
+	p.Progress().Expect(games)
+	progress := startProgress(logger, p.Progress(), *progressMode)
+	p.Start(ctx)
+
+	for _, g := range games {
+		// Once cancelled the remaining games are only reported as unprocessed
+		_ = p.Process(ctx, g)
+	}
+
+	p.Wait()
+	progress.stop()
+
+	if index != nil && !options.DryRun {
+		if err := index.Save(); err != nil {
+			logger.Errorf("Error saving library index: %s", err)
+		}
+	}
+
+	report := processor.NewReport(options.DryRun, results.Results())
+	if *outputFormat == outputJSON {
+		if err := printJSON(os.Stdout, report); err != nil {
+			return err
+		}
+	} else if err := printSummary(report, os.Stdout); err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return interruptedError(fmt.Errorf("interrupted, %d screenshots left unprocessed", report.Summary.Cancelled))
+	}
+	if report.Summary.Errors > 0 {
+		return partialFailureError(fmt.Errorf("finished with %d errors", report.Summary.Errors))
+	}
+	return nil
+}
+
+// parseMode parses an octal file mode.
+func parseMode(value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if mode == 0 || mode > uint64(os.ModePerm) {
+		return 0, errors.New("must be between 1 and 0777")
+	}
+	return os.FileMode(mode), nil
+}
+
+// parseOwner parses an owner as user[:group], with names or IDs. If no group
+// is provided the primary group of the user is used.
+func parseOwner(value string) (*models.Owner, error) {
+	userName, groupName, hasGroup := strings.Cut(value, ":")
+
+	owner := &models.Owner{}
+	u, err := user.Lookup(userName)
+	if err != nil {
+		if u, err = user.LookupId(userName); err != nil {
+			return nil, err
+		}
+	}
+	if owner.UID, err = strconv.Atoi(u.Uid); err != nil {
+		return nil, fmt.Errorf("user %s has no numeric ID", userName)
+	}
+
+	gid := u.Gid
+	if hasGroup {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return nil, err
+			}
+		}
+		gid = g.Gid
+	}
+	if owner.GID, err = strconv.Atoi(gid); err != nil {
+		return nil, fmt.Errorf("group %s has no numeric ID", gid)
+	}
+
+	return owner, nil
+}
+
+// parseBytes parses a size in bytes with an optional K, M or G suffix (powers
+// of 1024).
+func parseBytes(value string) (int64, error) {
+	value = strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B")
+	multiplier := int64(1)
+	if i := strings.IndexAny(value, "KMG"); i >= 0 && i == len(value)-1 {
+		multiplier = int64(1) << (10 * (strings.IndexByte("KMG", value[i]) + 1))
+		value = value[:i]
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if size <= 0 {
+		return 0, errors.New("must be positive")
+	}
+	return size * multiplier, nil
+}
+
+// printSummary prints the results of the games where something was copied,
+// or that had conflicts, errors or screenshots left unprocessed, followed by
+// the totals.
+func printSummary(report processor.Report, out io.Writer) error {
+	copied := "COPIED"
+	if report.DryRun {
+		copied = "TO COPY"
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "GAME\tPLATFORM\t%s\tSKIPPED\tDUPLICATES\tCONFLICTS\tERRORS\tCANCELLED\tSIZE\n", copied)
+	for _, game := range report.Games {
+		summary := game.Summary()
+		if summary.Copied == 0 && summary.Conflicts == 0 && summary.Errors == 0 && summary.Cancelled == 0 {
+			continue
+		}
+		name := game.Name
+		if name == "" {
+			name = game.ID
+		}
+		if game.Notes != "" {
+			name += " (" + game.Notes + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", name, game.Platform, summary.Copied, summary.Skipped, summary.Duplicates, summary.Conflicts, summary.Errors, summary.Cancelled, formatBytes(summary.Bytes))
+	}
+	total := report.Summary
+	fmt.Fprintf(w, "TOTAL (%d games)\t\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", total.Games, total.Copied, total.Skipped, total.Duplicates, total.Conflicts, total.Errors, total.Cancelled, formatBytes(total.Bytes))
+	return w.Flush()
+}
+
+// newArtworkResolver returns a resolver looking for covers in the provided
+// sources, in order. Sources that aren't configured are skipped.
+func newArtworkResolver(logger *logrus.Logger, platformNormalizer *platforms.Normalizer, sources []string, coversPath, steamGridDBURL, steamGridDBAPIKey string) models.ArtworkResolver {
+	var resolvers []models.ArtworkResolver
+
+	for _, source := range sources {
+		switch strings.TrimSpace(source) {
+		case "local":
+			if coversPath != "" {
+				resolvers = append(resolvers, artwork.NewLocalResolver(coversPath))
+			}
+		case "provider":
+			resolvers = append(resolvers, artwork.NewProviderResolver())
+		case "steam":
+			resolvers = append(resolvers, artwork.NewSteamResolver())
+		case "libretro":
+			resolvers = append(resolvers, artwork.NewLibretroResolver(platformNormalizer))
+		case "steamgriddb":
+			if steamGridDBAPIKey != "" {
+				resolvers = append(resolvers, artwork.NewSteamGridDBResolver(steamGridDBURL, steamGridDBAPIKey))
+			}
+		default:
+			logger.Warnf("Unknown cover source %s, ignoring.", source)
+		}
+	}
+
+	return artwork.NewChain(logger, resolvers...)
+}
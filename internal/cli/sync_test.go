@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+)
+
+// TestPrintSummaryCancelled
+// Tests that the summary of an interrupted run shows the screenshots left
+// unprocessed and the duplicates apart from the skipped ones
+func TestPrintSummaryCancelled(t *testing.T) {
+	report := processor.NewReport(false, []processor.GameResult{
+		{Provider: "steam", Platform: "PC", Name: "Started", Screenshots: []processor.ScreenshotResult{
+			{Action: processor.ActionCopy, Bytes: 2048},
+			{Action: processor.ActionSkip},
+			{Action: processor.ActionDuplicate},
+			{Action: processor.ActionCancelled},
+		}},
+		{Provider: "steam", Platform: "PC", Name: "Unprocessed", Screenshots: []processor.ScreenshotResult{
+			{Action: processor.ActionCancelled},
+			{Action: processor.ActionCancelled},
+		}},
+	})
+
+	var out bytes.Buffer
+	if err := printSummary(report, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"GAME", "PLATFORM", "COPIED", "SKIPPED", "DUPLICATES", "CONFLICTS", "ERRORS", "CANCELLED", "SIZE"},
+		{"Started", "PC", "1", "1", "1", "0", "0", "1", "2.0", "KiB"},
+		{"Unprocessed", "PC", "0", "0", "0", "0", "0", "2", "0", "B"},
+		{"TOTAL", "(2", "games)", "1", "1", "1", "0", "0", "3", "2.0", "KiB"},
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got:\n%s", len(expected), out.String())
+	}
+	for i, line := range lines {
+		if fields := strings.Fields(line); strings.Join(fields, " ") != strings.Join(expected[i], " ") {
+			t.Errorf("line %d = %q, expected %q", i, strings.Join(fields, " "), strings.Join(expected[i], " "))
+		}
+	}
+}
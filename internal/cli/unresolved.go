@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/identity"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultAliasesPath returns the path of the aliases file in the user
+// configuration directory, where names for unresolved games are stored.
+func defaultAliasesPath() string {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(userConfigDir, "games-screenshot-manager", "aliases.json")
+}
+
+// reportUnresolved logs the games that couldn't be named, which will be
+// stored in a folder named after their ID.
+func reportUnresolved(logger *logrus.Logger, games []*models.Game, aliasesPath string) {
+	if len(games) == 0 {
+		return
+	}
+
+	logger.Warnf("Found %d games without a name, they will be stored using their ID:", len(games))
+	for _, game := range games {
+		logger.Warnf("  %s (%d screenshots)", identity.GameAlias(game), len(game.Screenshots))
+	}
+	logger.Warnf("Name them using the -interactive flag or adding them to %s", aliasesPath)
+}
+
+// promptNames asks for the name of each unresolved game, returning the
+// aliases for the games that were named.
+func promptNames(games []*models.Game, in io.Reader, out io.Writer) map[string]string {
+	aliases := make(map[string]string)
+	reader := bufio.NewReader(in)
+
+	for _, game := range games {
+		fmt.Fprintf(out, "Name for %s game %s (%d screenshots", game.Provider, game.ID, len(game.Screenshots))
+		if len(game.Screenshots) > 0 {
+			fmt.Fprintf(out, ", like %s", game.Screenshots[0].Path)
+		}
+		fmt.Fprint(out, "), leave empty to skip: ")
+
+		line, err := reader.ReadString('\n')
+		name := strings.TrimSpace(line)
+		if name != "" {
+			game.Name = name
+			aliases[identity.GameAlias(game)] = name
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	return aliases
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+	"github.com/fmartingr/games-screenshot-manager/pkg/library"
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+	"github.com/sirupsen/logrus"
+)
+
+// verifyGames prints the screenshots missing or different in the output path,
+// returning how many were checked and how many have problems.
+func verifyGames(logger *logrus.Logger, p *processor.Processor, games []*models.Game, out io.Writer) (checked, problems int, err error) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tGAME\tSOURCE\tDESTINATION")
+
+	for _, game := range games {
+		results, err := p.Verify(game)
+		if err != nil {
+			logger.Errorf("Error verifying game %s from %s: %s", displayName(game), game.Provider, err)
+			problems++
+		}
+
+		for _, result := range results {
+			checked++
+			if result.Status == processor.StatusOK {
+				continue
+			}
+			problems++
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Status, displayName(game), result.Screenshot.Path, result.Destination)
+		}
+	}
+
+	return checked, problems, w.Flush()
+}
+
+// runVerify handles the `verify` command.
+func runVerify(ctx context.Context, logger *logrus.Logger, args []string) error {
+	flagSet := flag.NewFlagSet("gsm verify", flag.ExitOnError)
+	env := newEnvironment(logger)
+	env.flags(flagSet)
+	env.providerFlags(flagSet)
+	options := models.Options{}
+	outputFlags(flagSet, &options)
+	useIndex := flagSet.Bool("index", true, "Use the index of the output path ("+library.IndexFileName+") to find screenshots stored under another name")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateOutputOptions(options); err != nil {
+		return err
+	}
+
+	if err := env.open(); err != nil {
+		return err
+	}
+	defer env.close()
+
+	games, err := env.findGames(ctx)
+	if err != nil {
+		return err
+	}
+
+	p, err := processor.NewProcessor(logger, nil, options, nil)
+	if err != nil {
+		return configError(err)
+	}
+	if *useIndex {
+		hasher, err := hashing.NewHasher(options.HashAlgorithm)
+		if err != nil {
+			return configError(err)
+		}
+		index, err := library.OpenIndex(logger, options.OutputPath, hasher)
+		if err != nil {
+			return fmt.Errorf("error opening library index: %s", err)
+		}
+		p.UseIndex(index)
+	}
+	checked, problems, err := verifyGames(logger, p, games, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Checked %d screenshots, %d problems found\n", checked, problems)
+	if problems > 0 {
+		return partialFailureError(fmt.Errorf("the output path doesn't match the %s screenshots", env.providerName))
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrArtworkNotFound = errors.New("artwork not found")
+
+// ArtworkResolver finds covers for a game from a particular source.
+type ArtworkResolver interface {
+	Resolve(ctx context.Context, game *Game) ([]Cover, error)
+}
@@ -1,15 +1,53 @@
 package models
 
 import (
+	"context"
 	"errors"
 	"time"
 )
 
 var ErrCacheKeyDontExist = errors.New("cache key don't exist")
 
+// CacheMetadata holds details about a cached value.
+type CacheMetadata struct {
+	StoredAt     time.Time `json:"stored_at"`
+	SourceURL    string    `json:"source_url,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+}
+
 type Cache interface {
 	Delete(key string) error
 	Get(key string) (string, error)
 	GetExpiry(key string, expiration time.Duration) (string, error)
+	GetMetadata(key string) (CacheMetadata, error)
 	Put(key, value string) error
+	PutWithMetadata(key, value string, metadata CacheMetadata) error
+	// Keys returns the keys stored in the cache, without the ones stored in
+	// its namespaces.
+	Keys() ([]string, error)
+	// Size returns the size in bytes of the value stored for a key.
+	Size(key string) (int64, error)
+	// Namespace returns a cache whose keys don't collide with the keys of
+	// this cache or other namespaces.
+	Namespace(name string) Cache
+	// Namespaces returns the names of the namespaces holding values.
+	Namespaces() ([]string, error)
+	// Clear removes all the values in the cache and its namespaces.
+	Clear() error
+}
+
+// BatchCache is implemented by caches able to store many values at once
+// efficiently, making them suitable to store indexed records.
+type BatchCache interface {
+	Cache
+	PutBatch(values map[string]string, metadata CacheMetadata) error
+	// ForEach calls fn with every key and value stored in the cache, without
+	// the ones stored in its namespaces.
+	ForEach(fn func(key, value string) error) error
+}
+
+// CacheWarmer is implemented by providers able to fill their cache in advance.
+type CacheWarmer interface {
+	WarmCache(ctx context.Context) error
 }
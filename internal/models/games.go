@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -15,7 +16,43 @@ type Game struct {
 	Provider    string
 	Screenshots []Screenshot
 	Notes       string
-	CoverURL    string
+	Covers      []Cover
+
+	// Label is the full name of the game in the source, when Name has been
+	// cleaned of tags like the region or revision.
+	Label string
+}
+
+// Cover is an image for a game. Kind tells apart the different images a
+// source may offer (box art, title screen, ...) and URLs, which can also be
+// local paths, are tried in order until one of them can be retrieved.
+type Cover struct {
+	Kind string
+	URLs []string
+}
+
+func NewCover(kind string, urls ...string) Cover {
+	return Cover{
+		Kind: kind,
+		URLs: urls,
+	}
+}
+
+// FolderName returns the name of the folder the game screenshots are stored in.
+// Notes are used to tell apart variants of the same game (editions, sources or
+// launcher instances) unless mergeVariants is set, in which case all variants
+// share the same folder.
+func (game Game) FolderName(mergeVariants bool) string {
+	name := game.Name
+	if name == "" {
+		name = game.ID
+	}
+
+	if !mergeVariants && game.Notes != "" {
+		name = fmt.Sprintf("%s (%s)", name, game.Notes)
+	}
+
+	return name
 }
 
 func NewGame(id, name, platform, provider string) Game {
@@ -56,7 +93,9 @@ func NewScreenshotWithoutDestination(path string) Screenshot {
 	}
 }
 
-func AddScreenshotToGame(platform string, userGames []*Game, gameName string, screenshot Screenshot) []*Game {
+// AddScreenshotToGame appends the screenshot to the game with the given name,
+// creating it for the platform and provider if it wasn't found.
+func AddScreenshotToGame(platform, provider string, userGames []*Game, gameName string, screenshot Screenshot) []*Game {
 	var foundGame *Game
 	for gameIndex, game := range userGames {
 		if game.Name == gameName {
@@ -66,7 +105,7 @@ func AddScreenshotToGame(platform string, userGames []*Game, gameName string, sc
 	}
 
 	if foundGame == nil {
-		foundGame := Game{Name: gameName, ID: gameName, Platform: platform, Provider: platform}
+		foundGame := Game{Name: gameName, ID: gameName, Platform: platform, Provider: provider}
 		foundGame.Screenshots = append(foundGame.Screenshots, screenshot)
 		userGames = append(userGames, &foundGame)
 	}
@@ -0,0 +1,11 @@
+package models
+
+import "io"
+
+// Hasher computes the hashes used to compare the contents of files.
+type Hasher interface {
+	// Name of the algorithm, stored along with the hashes.
+	Name() string
+	// Hash returns the hex encoded hash of the contents.
+	Hash(r io.Reader) (string, error)
+}
@@ -1,9 +1,32 @@
 package models
 
+import (
+	"os"
+	"time"
+)
+
 type Options struct {
-	OutputPath        string
-	DryRun            bool
-	DownloadCovers    bool
-	ProcessBufferSize int
-	WorkersNum        int
+	OutputPath           string
+	DryRun               bool
+	DownloadCovers       bool
+	CoverVariants        []string
+	RefreshCovers        bool
+	MergeVariants        bool
+	GroupBy              string
+	ProcessBufferSize    int
+	WorkersNum           int
+	IOLimit              int64
+	FileMode             os.FileMode
+	DirMode              os.FileMode
+	PreserveTimes        bool
+	Owner                *Owner
+	HashAlgorithm        string
+	NearDuplicates       string
+	NearDuplicatesWindow time.Duration
+}
+
+// Owner is the user and group set on the copied files and created folders.
+type Owner struct {
+	UID int
+	GID int
 }
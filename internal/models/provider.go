@@ -1,13 +1,37 @@
 package models
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+var ErrInputPathRequired = errors.New("input path required")
 
 type ProviderOptions struct {
 	InputPath string
 }
 
 type Provider interface {
-	FindGames(options ProviderOptions) ([]*Game, error)
+	FindGames(ctx context.Context, options ProviderOptions) ([]*Game, error)
+}
+
+// ProviderInfo describes the options used by a provider.
+type ProviderInfo struct {
+	// InputPath describes what the input path should point to, empty if the
+	// provider doesn't use it.
+	InputPath         string
+	InputPathRequired bool
+}
+
+// ProviderDetector is implemented by providers able to tell if their
+// screenshots are available on this system.
+type ProviderDetector interface {
+	Info() ProviderInfo
+	// Detect returns the path the screenshots would be read from, or an error
+	// if not found.
+	Detect(options ProviderOptions) (string, error)
 }
 
 type ProviderFactory func(logger *logrus.Logger, cache Cache) Provider
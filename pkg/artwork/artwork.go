@@ -0,0 +1,79 @@
+package artwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrNotAnImage = errors.New("not an image")
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+	"image/bmp":  ".bmp",
+}
+
+// Extension returns the file extension to use for an image content type.
+func Extension(contentType string) (string, error) {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	extension, exists := imageExtensions[mediaType]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrNotAnImage, contentType)
+	}
+	return extension, nil
+}
+
+// Chain resolves covers from several resolvers, in order.
+type Chain struct {
+	logger    *logrus.Entry
+	resolvers []models.ArtworkResolver
+}
+
+func (c *Chain) Resolve(ctx context.Context, game *models.Game) ([]models.Cover, error) {
+	var result []models.Cover
+	seen := make(map[string]bool)
+
+	for _, resolver := range c.resolvers {
+		covers, err := resolver.Resolve(ctx, game)
+		if err != nil {
+			if !errors.Is(err, models.ErrArtworkNotFound) && !errors.Is(err, helpers.ErrOffline) {
+				c.logger.Errorf("Error resolving covers for game %s from %s: %s", game.Name, game.Provider, err)
+			}
+			continue
+		}
+
+		for _, cover := range covers {
+			var urls []string
+			for _, coverURL := range cover.URLs {
+				if !seen[coverURL] {
+					seen[coverURL] = true
+					urls = append(urls, coverURL)
+				}
+			}
+			if len(urls) > 0 {
+				result = append(result, models.NewCover(cover.Kind, urls...))
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, models.ErrArtworkNotFound
+	}
+
+	return result, nil
+}
+
+func NewChain(logger *logrus.Logger, resolvers ...models.ArtworkResolver) *Chain {
+	return &Chain{
+		logger:    logger.WithField("from", "artwork"),
+		resolvers: resolvers,
+	}
+}
@@ -0,0 +1,144 @@
+package artwork
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/sirupsen/logrus"
+)
+
+// Expiration is how long stored artwork is used before being revalidated.
+const Expiration = 7 * 24 * time.Hour
+
+// Fetcher retrieves artwork from URLs or local paths, verifying that the
+// contents are an image. Remote artwork is stored in the cache and revalidated
+// using ETag and Last-Modified headers on later requests.
+type Fetcher struct {
+	logger  *logrus.Entry
+	cache   models.Cache
+	refresh bool
+}
+
+func cacheKey(source string) string {
+	hash := sha256.Sum256([]byte(source))
+	return "artwork-" + hex.EncodeToString(hash[:])
+}
+
+// Fetch returns the contents and content type of the artwork.
+func (f *Fetcher) Fetch(ctx context.Context, source string) ([]byte, string, error) {
+	var contents []byte
+	var err error
+
+	parsedURL, parseErr := url.Parse(source)
+	if parseErr != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		path := source
+		if parseErr == nil && parsedURL.Scheme == "file" {
+			path = parsedURL.Path
+		}
+		contents, err = os.ReadFile(path)
+	} else {
+		contents, err = f.fetchURL(ctx, source)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Don't trust the content type sent by servers, a missing image may be an
+	// HTML error page.
+	contentType := http.DetectContentType(contents)
+	if _, err := Extension(contentType); err != nil {
+		return nil, "", err
+	}
+
+	return contents, contentType, nil
+}
+
+func (f *Fetcher) fetchURL(ctx context.Context, source string) ([]byte, error) {
+	key := cacheKey(source)
+	var metadata models.CacheMetadata
+	var cached []byte
+
+	if f.cache != nil && !f.refresh {
+		if contents, err := f.cache.Get(key); err == nil {
+			cached = []byte(contents)
+			if metadata, err = f.cache.GetMetadata(key); err != nil {
+				f.logger.Debugf("Error reading metadata for %s: %s", source, err)
+			}
+		}
+	}
+
+	client := helpers.HTTP()
+	request, err := client.NewRequest(ctx, "GET", source)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if metadata.ETag != "" {
+			request.Header.Set("If-None-Match", metadata.ETag)
+		}
+		if metadata.LastModified != "" {
+			request.Header.Set("If-Modified-Since", metadata.LastModified)
+		}
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		// Also used in offline mode
+		if cached != nil && ctx.Err() == nil {
+			f.logger.Debugf("Error revalidating %s, using cached copy: %s", source, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified && cached != nil {
+		return cached, nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.cache != nil {
+		if _, err := Extension(http.DetectContentType(contents)); err != nil {
+			return nil, err
+		}
+
+		err := f.cache.PutWithMetadata(key, string(contents), models.CacheMetadata{
+			SourceURL:    source,
+			ETag:         response.Header.Get("ETag"),
+			LastModified: response.Header.Get("Last-Modified"),
+		})
+		if err != nil {
+			f.logger.Errorf("Error caching artwork %s: %s", source, err)
+		}
+	}
+
+	return contents, nil
+}
+
+// NewFetcher returns a fetcher storing artwork in the provided cache, which
+// can be nil to disable caching. If refresh is set cached artwork is ignored
+// and downloaded again.
+func NewFetcher(logger *logrus.Logger, cache models.Cache, refresh bool) *Fetcher {
+	return &Fetcher{
+		logger:  logger.WithField("from", "artwork.fetcher"),
+		cache:   cache,
+		refresh: refresh,
+	}
+}
@@ -0,0 +1,70 @@
+package artwork_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/artwork"
+	"github.com/fmartingr/games-screenshot-manager/pkg/cache"
+	"github.com/sirupsen/logrus"
+)
+
+// pngHeader is enough for the content type to be detected as a PNG image
+var pngHeader = []byte("\x89PNG\r\n\x1a\n0000")
+
+// TestFetcherRevalidation
+// Tests that cached artwork is revalidated using the ETag and served from the
+// cache when not modified
+func TestFetcherRevalidation(t *testing.T) {
+	requests := 0
+	notModified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(pngHeader)
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	fetcher := artwork.NewFetcher(logger, cache.NewMemoryCache(logger), false)
+
+	for i := 0; i < 2; i++ {
+		contents, contentType, err := fetcher.Fetch(context.Background(), server.URL+"/cover.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType != "image/png" {
+			t.Errorf("Wrong content type: %s (should be image/png)", contentType)
+		}
+		if string(contents) != string(pngHeader) {
+			t.Errorf("Wrong contents on request %d", i)
+		}
+	}
+
+	if requests != 2 || notModified != 1 {
+		t.Errorf("Cover was not revalidated: %d requests, %d not modified", requests, notModified)
+	}
+}
+
+// TestFetcherNotAnImage
+// Tests that error pages are not taken as artwork
+func TestFetcherNotAnImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("<html><body>Not found</body></html>"))
+	}))
+	defer server.Close()
+
+	fetcher := artwork.NewFetcher(logrus.New(), nil, false)
+	if _, _, err := fetcher.Fetch(context.Background(), server.URL+"/cover.png"); !errors.Is(err, artwork.ErrNotAnImage) {
+		t.Errorf("Expected ErrNotAnImage, got %v", err)
+	}
+}
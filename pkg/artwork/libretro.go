@@ -0,0 +1,89 @@
+package artwork
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/platforms"
+)
+
+const libretroThumbnailsURL = "https://thumbnails.libretro.com/"
+
+// libretroThumbnailTypes are the kinds of thumbnails available on the libretro
+// thumbnail server along with the directory holding them, in order of
+// preference.
+var libretroThumbnailTypes = []struct {
+	kind      string
+	directory string
+}{
+	{kind: "boxart", directory: "Named_Boxarts"},
+	{kind: "title", directory: "Named_Titles"},
+	{kind: "snap", directory: "Named_Snaps"},
+}
+
+// libretroForbiddenCharacters are the characters libretro doesn't allow on
+// thumbnail names, as per the thumbnail naming rules.
+var libretroForbiddenCharacters = strings.NewReplacer(
+	"&", "_", "*", "_", "/", "_", ":", "_", "`", "_",
+	"<", "_", ">", "_", "?", "_", "\\", "_", "|", "_",
+)
+
+func formatLibretroThumbnailURL(system, directory, name string) string {
+	return libretroThumbnailsURL + url.PathEscape(system) + "/" + directory + "/" + url.PathEscape(libretroForbiddenCharacters.Replace(name)) + ".png"
+}
+
+// LibretroCovers returns the thumbnails from the libretro thumbnail server for
+// a game on a libretro database system, trying the provided names in order.
+func LibretroCovers(system string, names ...string) []models.Cover {
+	var result []models.Cover
+
+	for _, thumbnailType := range libretroThumbnailTypes {
+		var urls []string
+		for _, name := range names {
+			if name != "" {
+				urls = append(urls, formatLibretroThumbnailURL(system, thumbnailType.directory, name))
+			}
+		}
+		if len(urls) > 0 {
+			result = append(result, models.NewCover(thumbnailType.kind, urls...))
+		}
+	}
+
+	return result
+}
+
+// LibretroResolver returns the thumbnails from the libretro thumbnail server
+// for games on platforms known to the libretro databases.
+type LibretroResolver struct {
+	platforms *platforms.Normalizer
+}
+
+func (r *LibretroResolver) Resolve(ctx context.Context, game *models.Game) ([]models.Cover, error) {
+	if game.Name == "" {
+		return nil, models.ErrArtworkNotFound
+	}
+
+	// Thumbnails use the full names from the databases, so the label is tried
+	// before the cleaned name
+	names := []string{game.Name}
+	if game.Label != "" && game.Label != game.Name {
+		names = []string{game.Label, game.Name}
+	}
+
+	// libretro databases are named `<Vendor> - <System>`
+	for _, alias := range r.platforms.Aliases(game.Platform) {
+		if strings.Contains(alias, " - ") {
+			return LibretroCovers(alias, names...), nil
+		}
+	}
+
+	return nil, models.ErrArtworkNotFound
+}
+
+func NewLibretroResolver(platforms *platforms.Normalizer) *LibretroResolver {
+	return &LibretroResolver{
+		platforms: platforms,
+	}
+}
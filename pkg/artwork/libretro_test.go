@@ -0,0 +1,33 @@
+package artwork_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/artwork"
+	"github.com/fmartingr/games-screenshot-manager/pkg/platforms"
+)
+
+// TestLibretroResolverLabel
+// Tests that the full label is tried before the cleaned name, as thumbnails
+// are named after the databases
+func TestLibretroResolverLabel(t *testing.T) {
+	resolver := artwork.NewLibretroResolver(platforms.NewNormalizer())
+	game := models.Game{Name: "Game", Label: "Game (USA) (Rev 1)", Platform: "Sony - PlayStation"}
+
+	covers, err := resolver.Resolve(context.Background(), &game)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(covers) == 0 || len(covers[0].URLs) != 2 {
+		t.Fatalf("expected two URLs per cover, got %+v", covers)
+	}
+	if !strings.HasSuffix(covers[0].URLs[0], "/Game%20%28USA%29%20%28Rev%201%29.png") {
+		t.Errorf("expected the label first, got %s", covers[0].URLs[0])
+	}
+	if !strings.HasSuffix(covers[0].URLs[1], "/Game.png") {
+		t.Errorf("expected the name second, got %s", covers[0].URLs[1])
+	}
+}
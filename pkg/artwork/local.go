@@ -0,0 +1,45 @@
+package artwork
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+var localImageExtensions = []string{".png", ".jpg", ".jpeg", ".webp"}
+
+// LocalResolver returns user supplied covers from a directory, looking for
+// `<platform>/<game name>.<extension>` first and `<game name>.<extension>`
+// otherwise.
+type LocalResolver struct {
+	path string
+}
+
+func (r *LocalResolver) Resolve(ctx context.Context, game *models.Game) ([]models.Cover, error) {
+	var urls []string
+	name := game.FolderName(true)
+
+	for _, directory := range []string{filepath.Join(r.path, game.Platform), r.path} {
+		for _, extension := range localImageExtensions {
+			path := filepath.Join(directory, name+extension)
+			if _, err := os.Stat(path); err == nil {
+				urls = append(urls, path)
+			}
+		}
+	}
+
+	if len(urls) == 0 {
+		return nil, models.ErrArtworkNotFound
+	}
+
+	return []models.Cover{models.NewCover("local", urls...)}, nil
+}
+
+func NewLocalResolver(path string) *LocalResolver {
+	return &LocalResolver{
+		path: helpers.ExpandUser(path),
+	}
+}
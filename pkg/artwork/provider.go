@@ -0,0 +1,21 @@
+package artwork
+
+import (
+	"context"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+)
+
+// ProviderResolver returns the covers set on the game by its provider.
+type ProviderResolver struct{}
+
+func (r *ProviderResolver) Resolve(ctx context.Context, game *models.Game) ([]models.Cover, error) {
+	if len(game.Covers) == 0 {
+		return nil, models.ErrArtworkNotFound
+	}
+	return game.Covers, nil
+}
+
+func NewProviderResolver() *ProviderResolver {
+	return &ProviderResolver{}
+}
@@ -0,0 +1,49 @@
+package artwork
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+)
+
+const steamProviderName = "steam"
+const steamCDNURL = "https://cdn.cloudflare.steamstatic.com/steam/apps/%d/%s"
+
+// steamImages are the images available on the Steam CDN for every app, in
+// order of preference.
+var steamImages = []struct {
+	kind     string
+	fileName string
+}{
+	{kind: "library", fileName: "library_600x900.jpg"},
+	{kind: "header", fileName: "header.jpg"},
+	{kind: "capsule", fileName: "capsule_616x353.jpg"},
+}
+
+// SteamResolver returns the images from the Steam CDN for games found by the
+// Steam provider.
+type SteamResolver struct{}
+
+func (r *SteamResolver) Resolve(ctx context.Context, game *models.Game) ([]models.Cover, error) {
+	if game.Provider != steamProviderName {
+		return nil, models.ErrArtworkNotFound
+	}
+
+	// Non-Steam games added to the library don't have a valid app ID
+	appID, err := strconv.ParseUint(game.ID, 10, 32)
+	if err != nil {
+		return nil, models.ErrArtworkNotFound
+	}
+
+	var result []models.Cover
+	for _, image := range steamImages {
+		result = append(result, models.NewCover(image.kind, fmt.Sprintf(steamCDNURL, appID, image.fileName)))
+	}
+	return result, nil
+}
+
+func NewSteamResolver() *SteamResolver {
+	return &SteamResolver{}
+}
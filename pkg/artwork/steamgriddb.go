@@ -0,0 +1,117 @@
+package artwork
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+const DefaultSteamGridDBURL = "https://www.steamgriddb.com/api/v2"
+
+type steamGridDBResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type steamGridDBGame struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type steamGridDBImage struct {
+	URL string `json:"url"`
+}
+
+// SteamGridDBResolver returns the grids (covers) from a SteamGridDB compatible
+// API. Steam games are looked up by their app ID, other games by name.
+type SteamGridDBResolver struct {
+	baseURL string
+	apiKey  string
+}
+
+func (r *SteamGridDBResolver) get(ctx context.Context, path string, result interface{}) error {
+	client := helpers.HTTP()
+	request, err := client.NewRequest(ctx, "GET", r.baseURL+path)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+r.apiKey)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return models.ErrArtworkNotFound
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d from steamgriddb", response.StatusCode)
+	}
+
+	var payload steamGridDBResponse
+	if err := json.NewDecoder(response.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("error decoding steamgriddb response: %s", err)
+	}
+	if !payload.Success {
+		return models.ErrArtworkNotFound
+	}
+
+	return json.Unmarshal(payload.Data, result)
+}
+
+func (r *SteamGridDBResolver) Resolve(ctx context.Context, game *models.Game) ([]models.Cover, error) {
+	gridsPath := ""
+
+	if appID, err := strconv.ParseUint(game.ID, 10, 32); err == nil && game.Provider == steamProviderName {
+		gridsPath = fmt.Sprintf("/grids/steam/%d", appID)
+	} else if game.Name != "" {
+		var games []steamGridDBGame
+		if err := r.get(ctx, "/search/autocomplete/"+url.PathEscape(game.Name), &games); err != nil {
+			return nil, err
+		}
+		for _, g := range games {
+			if strings.EqualFold(g.Name, game.Name) {
+				gridsPath = fmt.Sprintf("/grids/game/%d", g.ID)
+				break
+			}
+		}
+	}
+
+	if gridsPath == "" {
+		return nil, models.ErrArtworkNotFound
+	}
+
+	var images []steamGridDBImage
+	if err := r.get(ctx, gridsPath, &images); err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, image := range images {
+		urls = append(urls, image.URL)
+	}
+	if len(urls) == 0 {
+		return nil, models.ErrArtworkNotFound
+	}
+
+	return []models.Cover{models.NewCover("grid", urls...)}, nil
+}
+
+func NewSteamGridDBResolver(baseURL, apiKey string) *SteamGridDBResolver {
+	if baseURL == "" {
+		baseURL = DefaultSteamGridDBURL
+	}
+	return &SteamGridDBResolver{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+	}
+}
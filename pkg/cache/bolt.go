@@ -0,0 +1,338 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+const boltCacheFileName = "cache.db"
+
+// Buckets used by the bolt cache, following the same layout as the file
+// cache: namespaces are nested buckets inside the namespaces bucket.
+var (
+	boltValuesBucket     = []byte("values")
+	boltMetadataBucket   = []byte("metadata")
+	boltNamespacesBucket = []byte("namespaces")
+	// boltBatchBucket holds the metadata of the last batch, shared by all the
+	// values stored with it.
+	boltBatchBucket      = []byte("batch")
+	boltBatchMetadataKey = []byte("metadata")
+)
+
+var errBoltBucketDontExist = errors.New("bucket don't exist")
+
+// BoltCache stores values in a bbolt database, making lookups of single
+// records fast without reading large values into memory.
+type BoltCache struct {
+	logger     *logrus.Entry
+	db         *bolt.DB
+	namespaces []string
+}
+
+// bucket returns the bucket with the provided name for the cache namespace,
+// creating it (and its parents) if create is set.
+func (c *BoltCache) bucket(tx *bolt.Tx, name []byte, create bool) (*bolt.Bucket, error) {
+	var path [][]byte
+	for _, namespace := range c.namespaces {
+		path = append(path, boltNamespacesBucket, []byte(namespace))
+	}
+	if name != nil {
+		path = append(path, name)
+	}
+
+	var bucket *bolt.Bucket
+	for i, bucketName := range path {
+		var err error
+		switch {
+		case create && i == 0:
+			bucket, err = tx.CreateBucketIfNotExists(bucketName)
+		case create:
+			bucket, err = bucket.CreateBucketIfNotExists(bucketName)
+		case i == 0:
+			bucket = tx.Bucket(bucketName)
+		default:
+			bucket = bucket.Bucket(bucketName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if bucket == nil {
+			return nil, errBoltBucketDontExist
+		}
+	}
+
+	return bucket, nil
+}
+
+func (c *BoltCache) Get(key string) (result string, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		bucket, err := c.bucket(tx, boltValuesBucket, false)
+		if err != nil {
+			return models.ErrCacheKeyDontExist
+		}
+
+		value := bucket.Get([]byte(key))
+		if value == nil {
+			return models.ErrCacheKeyDontExist
+		}
+		result = string(value)
+		return nil
+	})
+	return
+}
+
+func (c *BoltCache) GetExpiry(key string, expiration time.Duration) (result string, err error) {
+	metadata, err := c.GetMetadata(key)
+	if err != nil {
+		return result, err
+	}
+
+	if metadata.StoredAt.Add(expiration).Before(time.Now()) {
+		if err := c.Delete(key); err != nil {
+			c.logger.Errorf("error deleting expired key %s: %s", key, err)
+		}
+		return result, models.ErrCacheKeyDontExist
+	}
+
+	return c.Get(key)
+}
+
+func (c *BoltCache) GetMetadata(key string) (metadata models.CacheMetadata, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		values, err := c.bucket(tx, boltValuesBucket, false)
+		if err != nil || values.Get([]byte(key)) == nil {
+			return models.ErrCacheKeyDontExist
+		}
+
+		var value []byte
+		if bucket, err := c.bucket(tx, boltMetadataBucket, false); err == nil {
+			value = bucket.Get([]byte(key))
+		}
+		// Values stored in a batch have no metadata of their own
+		if value == nil {
+			if bucket, err := c.bucket(tx, boltBatchBucket, false); err == nil {
+				value = bucket.Get(boltBatchMetadataKey)
+			}
+		}
+		if value != nil {
+			if err := json.Unmarshal(value, &metadata); err != nil {
+				c.logger.Errorf("error reading metadata for key %s: %s", key, err)
+			}
+		}
+		return nil
+	})
+	return
+}
+
+func (c *BoltCache) Put(key, value string) error {
+	return c.PutWithMetadata(key, value, models.CacheMetadata{})
+}
+
+func (c *BoltCache) PutWithMetadata(key, value string, metadata models.CacheMetadata) error {
+	return c.put(map[string]string{key: value}, metadata, false)
+}
+
+// PutBatch stores all the values in a single transaction. The metadata is
+// stored once for all of them, replacing the one of the previous batch.
+func (c *BoltCache) PutBatch(values map[string]string, metadata models.CacheMetadata) error {
+	return c.put(values, metadata, true)
+}
+
+func (c *BoltCache) put(values map[string]string, metadata models.CacheMetadata, batch bool) error {
+	if metadata.StoredAt.IsZero() {
+		metadata.StoredAt = time.Now()
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("error encoding cache metadata: %s", err)
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		valuesBucket, err := c.bucket(tx, boltValuesBucket, true)
+		if err != nil {
+			return err
+		}
+		metadataBucket, err := c.bucket(tx, boltMetadataBucket, true)
+		if err != nil {
+			return err
+		}
+
+		if batch {
+			batchBucket, err := c.bucket(tx, boltBatchBucket, true)
+			if err != nil {
+				return err
+			}
+			if err := batchBucket.Put(boltBatchMetadataKey, metadataJSON); err != nil {
+				return fmt.Errorf("error writting cache metadata: %s", err)
+			}
+		}
+
+		for key, value := range values {
+			if err := valuesBucket.Put([]byte(key), []byte(value)); err != nil {
+				return fmt.Errorf("error writting cache value: %s", err)
+			}
+
+			// Values in a batch use its metadata instead of the one they had
+			if batch {
+				err = metadataBucket.Delete([]byte(key))
+			} else {
+				err = metadataBucket.Put([]byte(key), metadataJSON)
+			}
+			if err != nil {
+				return fmt.Errorf("error writting cache metadata: %s", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (c *BoltCache) Delete(key string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltValuesBucket, boltMetadataBucket} {
+			bucket, err := c.bucket(tx, name, false)
+			if err != nil {
+				continue
+			}
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *BoltCache) Keys() (result []string, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		bucket, err := c.bucket(tx, boltValuesBucket, false)
+		if err != nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			result = append(result, string(k))
+			return nil
+		})
+	})
+	return
+}
+
+// ForEach reads all the values in a single transaction.
+func (c *BoltCache) ForEach(fn func(key, value string) error) error {
+	return c.db.View(func(tx *bolt.Tx) error {
+		bucket, err := c.bucket(tx, boltValuesBucket, false)
+		if err != nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			return fn(string(k), string(v))
+		})
+	})
+}
+
+func (c *BoltCache) Size(key string) (size int64, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		bucket, err := c.bucket(tx, boltValuesBucket, false)
+		if err != nil {
+			return models.ErrCacheKeyDontExist
+		}
+		value := bucket.Get([]byte(key))
+		if value == nil {
+			return models.ErrCacheKeyDontExist
+		}
+		size = int64(len(value))
+		return nil
+	})
+	return
+}
+
+// Namespace returns a cache stored in a nested bucket.
+func (c *BoltCache) Namespace(name string) models.Cache {
+	return &BoltCache{
+		logger:     c.logger.WithField("namespace", name),
+		db:         c.db,
+		namespaces: append(append([]string{}, c.namespaces...), name),
+	}
+}
+
+func (c *BoltCache) Namespaces() (result []string, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		bucket, err := c.bucket(tx, boltNamespacesBucket, false)
+		if err != nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			// Nested buckets have no value
+			if v == nil {
+				result = append(result, string(k))
+			}
+			return nil
+		})
+	})
+	sort.Strings(result)
+	return
+}
+
+func (c *BoltCache) Clear() error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltValuesBucket, boltMetadataBucket, boltBatchBucket, boltNamespacesBucket} {
+			var err error
+			if len(c.namespaces) == 0 {
+				err = tx.DeleteBucket(name)
+			} else {
+				var parent *bolt.Bucket
+				if parent, err = c.bucket(tx, nil, false); err == nil {
+					err = parent.DeleteBucket(name)
+				}
+			}
+			if err != nil && !errors.Is(err, bolt.ErrBucketNotFound) && !errors.Is(err, errBoltBucketDontExist) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close closes the database. Namespaces share the database of the root
+// cache, so closing them does nothing.
+func (c *BoltCache) Close() error {
+	if len(c.namespaces) > 0 {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// NewBoltCache returns a bolt cache stored in the user cache directory.
+func NewBoltCache(logger *logrus.Logger) (*BoltCache, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("error getting cache directory: %s", err)
+	}
+	return NewBoltCacheWithPath(logger, filepath.Join(userCacheDir, "games-screenshot-manager", boltCacheFileName))
+}
+
+// NewBoltCacheWithPath returns a bolt cache stored in the provided file.
+func NewBoltCacheWithPath(logger *logrus.Logger, path string) (*BoltCache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %s", err)
+	}
+
+	// Fail instead of waiting forever if another process holds the database
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening cache database: %s", err)
+	}
+
+	return &BoltCache{
+		logger: logger.WithField("from", "cache.bolt"),
+		db:     db,
+	}, nil
+}
@@ -0,0 +1,339 @@
+package cache_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/cache"
+	"github.com/sirupsen/logrus"
+)
+
+// testCacheConformance checks the behaviour every models.Cache implementation
+// must follow
+func testCacheConformance(t *testing.T, newCache func(t *testing.T) models.Cache) {
+	t.Run("GetMissing", func(t *testing.T) {
+		c := newCache(t)
+		if _, err := c.Get("missing"); !errors.Is(err, models.ErrCacheKeyDontExist) {
+			t.Errorf("Expected ErrCacheKeyDontExist, got %v", err)
+		}
+		if _, err := c.GetExpiry("missing", time.Hour); !errors.Is(err, models.ErrCacheKeyDontExist) {
+			t.Errorf("Expected ErrCacheKeyDontExist, got %v", err)
+		}
+	})
+
+	t.Run("PutGet", func(t *testing.T) {
+		c := newCache(t)
+		if err := c.Put("key", "value"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Put("key", "new value"); err != nil {
+			t.Fatal(err)
+		}
+		result, err := c.Get("key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != "new value" {
+			t.Errorf("Wrong value: %s (should be new value)", result)
+		}
+	})
+
+	t.Run("GetExpiry", func(t *testing.T) {
+		c := newCache(t)
+		if err := c.Put("key", "value"); err != nil {
+			t.Fatal(err)
+		}
+		if result, err := c.GetExpiry("key", time.Hour); err != nil || result != "value" {
+			t.Errorf("Expected valid value, got %q, %v", result, err)
+		}
+		if _, err := c.GetExpiry("key", -time.Second); !errors.Is(err, models.ErrCacheKeyDontExist) {
+			t.Errorf("Expected ErrCacheKeyDontExist for expired key, got %v", err)
+		}
+		// Expired keys are removed
+		if _, err := c.Get("key"); !errors.Is(err, models.ErrCacheKeyDontExist) {
+			t.Errorf("Expected ErrCacheKeyDontExist after expiration, got %v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		c := newCache(t)
+		if err := c.Put("key", "value"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Delete("key"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Get("key"); !errors.Is(err, models.ErrCacheKeyDontExist) {
+			t.Errorf("Expected ErrCacheKeyDontExist after delete, got %v", err)
+		}
+		if err := c.Delete("key"); err != nil {
+			t.Errorf("Deleting a missing key should not fail: %v", err)
+		}
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		c := newCache(t)
+		if _, err := c.GetMetadata("key"); !errors.Is(err, models.ErrCacheKeyDontExist) {
+			t.Errorf("Expected ErrCacheKeyDontExist, got %v", err)
+		}
+		if err := c.PutWithMetadata("key", "value", models.CacheMetadata{SourceURL: "https://example.com", ETag: `"etag"`}); err != nil {
+			t.Fatal(err)
+		}
+		metadata, err := c.GetMetadata("key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if metadata.SourceURL != "https://example.com" || metadata.ETag != `"etag"` || metadata.StoredAt.IsZero() {
+			t.Errorf("Wrong metadata: %+v", metadata)
+		}
+	})
+
+	t.Run("Namespace", func(t *testing.T) {
+		c := newCache(t)
+		namespace := c.Namespace("provider")
+		if err := c.Put("key", "root"); err != nil {
+			t.Fatal(err)
+		}
+		if err := namespace.Put("key", "namespace"); err != nil {
+			t.Fatal(err)
+		}
+		if result, err := c.Get("key"); err != nil || result != "root" {
+			t.Errorf("Expected root value, got %q, %v", result, err)
+		}
+		if result, err := namespace.Get("key"); err != nil || result != "namespace" {
+			t.Errorf("Expected namespace value, got %q, %v", result, err)
+		}
+	})
+
+	t.Run("Enumeration", func(t *testing.T) {
+		c := newCache(t)
+		c.Put("b", "12345")
+		c.Put("a/1", "1")
+		c.Namespace("provider").Put("key", "value")
+		c.Namespace("provider").Namespace("nested").Put("key", "value")
+
+		keys, err := c.Keys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(keys, []string{"a/1", "b"}) {
+			t.Errorf("Wrong keys: %v", keys)
+		}
+		namespaces, err := c.Namespaces()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(namespaces, []string{"provider"}) {
+			t.Errorf("Wrong namespaces: %v", namespaces)
+		}
+		keys, err = c.Namespace("provider").Keys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(keys, []string{"key"}) {
+			t.Errorf("Wrong namespace keys: %v", keys)
+		}
+		if size, err := c.Size("b"); err != nil || size != 5 {
+			t.Errorf("Size(b) = %d, %v (should be 5)", size, err)
+		}
+		if _, err := c.Size("missing"); !errors.Is(err, models.ErrCacheKeyDontExist) {
+			t.Errorf("Expected ErrCacheKeyDontExist, got %v", err)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		c := newCache(t)
+		c.Put("key", "value")
+		c.Namespace("provider").Put("key", "value")
+		other := c.Namespace("other")
+		other.Put("key", "value")
+
+		if err := c.Namespace("provider").Clear(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Namespace("provider").Get("key"); !errors.Is(err, models.ErrCacheKeyDontExist) {
+			t.Errorf("Expected ErrCacheKeyDontExist after clearing namespace, got %v", err)
+		}
+		if _, err := other.Get("key"); err != nil {
+			t.Errorf("Clearing a namespace should not affect others: %v", err)
+		}
+
+		if err := c.Clear(); err != nil {
+			t.Fatal(err)
+		}
+		for _, cache := range []models.Cache{c, other} {
+			if _, err := cache.Get("key"); !errors.Is(err, models.ErrCacheKeyDontExist) {
+				t.Errorf("Expected ErrCacheKeyDontExist after clear, got %v", err)
+			}
+		}
+	})
+
+	t.Run("UnsafeKeys", func(t *testing.T) {
+		c := newCache(t)
+		for _, key := range []string{"../escape", "/absolute", "a/b", "..", ".", "with spaces"} {
+			if err := c.Put(key, key); err != nil {
+				t.Fatalf("Put(%q): %s", key, err)
+			}
+		}
+		for _, key := range []string{"../escape", "/absolute", "a/b", "..", ".", "with spaces"} {
+			if result, err := c.Get(key); err != nil || result != key {
+				t.Errorf("Get(%q) = %q, %v", key, result, err)
+			}
+		}
+	})
+}
+
+func TestMemoryCache(t *testing.T) {
+	testCacheConformance(t, func(t *testing.T) models.Cache {
+		return cache.NewMemoryCache(logrus.New())
+	})
+}
+
+func TestFileCache(t *testing.T) {
+	testCacheConformance(t, func(t *testing.T) models.Cache {
+		return cache.NewFileCacheWithPath(logrus.New(), filepath.Join(t.TempDir(), "cache"))
+	})
+}
+
+func TestBoltCache(t *testing.T) {
+	testCacheConformance(t, func(t *testing.T) models.Cache {
+		c, err := cache.NewBoltCacheWithPath(logrus.New(), filepath.Join(t.TempDir(), "cache.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		return c
+	})
+}
+
+// TestBoltCacheBatch
+// Tests that values stored in a batch share its metadata, and that values
+// stored on their own keep theirs
+func TestBoltCacheBatch(t *testing.T) {
+	c, err := cache.NewBoltCacheWithPath(logrus.New(), filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.PutWithMetadata("1", "old", models.CacheMetadata{ETag: `"single"`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutBatch(map[string]string{"1": "one", "2": "two"}, models.CacheMetadata{ETag: `"batch"`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutWithMetadata("3", "three", models.CacheMetadata{ETag: `"single"`}); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, etag := range map[string]string{"1": `"batch"`, "2": `"batch"`, "3": `"single"`} {
+		metadata, err := c.GetMetadata(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if metadata.ETag != etag || metadata.StoredAt.IsZero() {
+			t.Errorf("Wrong metadata for %s: %+v (should have ETag %s)", key, metadata, etag)
+		}
+		if _, err := c.GetExpiry(key, time.Hour); err != nil {
+			t.Errorf("Expected valid value for %s, got %v", key, err)
+		}
+	}
+}
+
+// TestBoltCacheNamespaceClose
+// Tests that closing a namespace doesn't close the database shared with the
+// root cache
+func TestBoltCacheNamespaceClose(t *testing.T) {
+	c, err := cache.NewBoltCacheWithPath(logrus.New(), filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	namespace := c.Namespace("provider").(*cache.BoltCache)
+	if err := namespace.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put("key", "value"); err != nil {
+		t.Errorf("Expected the database to be open, got %v", err)
+	}
+}
+
+// TestFileCacheStaysInDirectory
+// Tests that keys can't be used to write outside of the cache directory
+func TestFileCacheStaysInDirectory(t *testing.T) {
+	dir := t.TempDir()
+	c := cache.NewFileCacheWithPath(logrus.New(), filepath.Join(dir, "cache"))
+	if err := c.Put("../../escaped", "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "cache" {
+		t.Errorf("Cache wrote outside of its directory: %v", entries)
+	}
+}
+
+// TestFileCacheLegacyKeys
+// Tests that values stored in the root of the cache directory by older
+// versions are moved to their namespace, keeping their stored time
+func TestFileCacheLegacyKeys(t *testing.T) {
+	dir := t.TempDir()
+	stored := time.Now().Add(-time.Hour).Truncate(time.Second)
+	legacyPath := filepath.Join(dir, "steam-applist")
+	if err := os.WriteFile(legacyPath, []byte("apps"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(legacyPath, stored, stored); err != nil {
+		t.Fatal(err)
+	}
+
+	c := cache.NewFileCacheWithPath(logrus.New(), dir)
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("Legacy file should be moved, got: %v", err)
+	}
+	if value, err := c.Namespace("steam").Get("steam-applist"); err != nil || value != "apps" {
+		t.Errorf("Get(steam/steam-applist) = %q, %v (should be apps)", value, err)
+	}
+	if metadata, err := c.Namespace("steam").GetMetadata("steam-applist"); err != nil || !metadata.StoredAt.Equal(stored) {
+		t.Errorf("Stored at %s, %v (should be %s)", metadata.StoredAt, err, stored)
+	}
+
+	// Files left behind are removed by Clear
+	if err := os.WriteFile(legacyPath, []byte("apps"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Cache directory should be empty after Clear: %v", entries)
+	}
+}
+
+// TestMemoryCacheEviction
+// Tests that the least recently used entries are evicted when bounded
+func TestMemoryCacheEviction(t *testing.T) {
+	c := cache.NewBoundedMemoryCache(logrus.New(), 2)
+	c.Put("a", "1")
+	c.Put("b", "2")
+	c.Get("a")
+	c.Put("c", "3")
+
+	if _, err := c.Get("b"); !errors.Is(err, models.ErrCacheKeyDontExist) {
+		t.Errorf("Least recently used key should have been evicted, got %v", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := c.Get(key); err != nil {
+			t.Errorf("Key %s should not have been evicted: %v", key, err)
+		}
+	}
+}
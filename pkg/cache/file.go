@@ -1,25 +1,102 @@
 package cache
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
 	"github.com/sirupsen/logrus"
 )
 
+// Layout of the cache directory. Values and their metadata are stored in
+// separate directories, and namespaces in their own sub-directory, so keys
+// can't collide with each other.
+const (
+	fileCacheValuesDir     = "values"
+	fileCacheMetadataDir   = "metadata"
+	fileCacheNamespacesDir = "namespaces"
+)
+
+// fileCacheLegacyKeys are the values stored in the root of the cache
+// directory by older versions, mapped to the namespace they belong to now.
+var fileCacheLegacyKeys = map[string]string{
+	"steam-applist": "steam",
+}
+
+// FileCache stores values as files in a directory. Keys are escaped so they
+// can't point outside of it and values are written atomically.
 type FileCache struct {
 	logger *logrus.Entry
 	path   string
 }
 
-func (c *FileCache) Get(key string) (result string, err error) {
-	path := filepath.Join(c.path, key)
+// escapeKey returns a file name for a key, escaping all characters but ASCII
+// letters, digits, `-`, `_` and `.` (unless leading, to avoid hidden files and
+// `..`) using `%XX` sequences.
+func escapeKey(key string) string {
+	var builder strings.Builder
+	for i := 0; i < len(key); i++ {
+		b := key[i]
+		isSafe := (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') ||
+			b == '-' || b == '_' || (b == '.' && i > 0)
+		if isSafe {
+			builder.WriteByte(b)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+func unescapeKey(name string) (string, error) {
+	return url.PathUnescape(name)
+}
+
+// listDir returns the unescaped names of the entries in a cache directory,
+// skipping temporary files.
+func (c *FileCache) listDir(dir string, suffix string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(c.path, dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var result []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name, err := unescapeKey(strings.TrimSuffix(entry.Name(), suffix))
+		if err != nil {
+			c.logger.Warnf("Unexpected file in cache directory: %s", entry.Name())
+			continue
+		}
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (c *FileCache) valuePath(key string) string {
+	return filepath.Join(c.path, fileCacheValuesDir, escapeKey(key))
+}
+
+func (c *FileCache) metadataPath(key string) string {
+	return filepath.Join(c.path, fileCacheMetadataDir, escapeKey(key)+".json")
+}
 
-	contents, err := os.ReadFile(path)
+func (c *FileCache) Get(key string) (result string, err error) {
+	contents, err := os.ReadFile(c.valuePath(key))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
 			return result, models.ErrCacheKeyDontExist
@@ -31,33 +108,149 @@ func (c *FileCache) Get(key string) (result string, err error) {
 }
 
 func (c *FileCache) GetExpiry(key string, expiration time.Duration) (result string, err error) {
-	path := filepath.Join(c.path, key)
-	info, err := os.Stat(path)
+	metadata, err := c.GetMetadata(key)
 	if err != nil {
-		return result, models.ErrCacheKeyDontExist
+		return result, err
 	}
 
-	if info.ModTime().Add(expiration).Before(time.Now()) {
-		c.Delete(key)
+	if metadata.StoredAt.Add(expiration).Before(time.Now()) {
+		if err := c.Delete(key); err != nil {
+			c.logger.Errorf("error deleting expired key %s: %s", key, err)
+		}
 		return result, models.ErrCacheKeyDontExist
 	}
 
 	return c.Get(key)
 }
 
+// GetMetadata returns the metadata stored with the value. Values without
+// metadata get the modification time of the value as the stored time.
+func (c *FileCache) GetMetadata(key string) (metadata models.CacheMetadata, err error) {
+	info, err := os.Stat(c.valuePath(key))
+	if err != nil {
+		return metadata, models.ErrCacheKeyDontExist
+	}
+
+	contents, err := os.ReadFile(c.metadataPath(key))
+	if err == nil {
+		if err := json.Unmarshal(contents, &metadata); err != nil {
+			c.logger.Errorf("error reading metadata for key %s: %s", key, err)
+		}
+	}
+
+	if metadata.StoredAt.IsZero() {
+		metadata.StoredAt = info.ModTime()
+	}
+
+	return metadata, nil
+}
+
 func (c *FileCache) Put(key, value string) error {
-	path := filepath.Join(c.path, key)
+	return c.PutWithMetadata(key, value, models.CacheMetadata{})
+}
+
+func (c *FileCache) PutWithMetadata(key, value string, metadata models.CacheMetadata) error {
+	if metadata.StoredAt.IsZero() {
+		metadata.StoredAt = time.Now()
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("error encoding cache metadata: %s", err)
+	}
+
+	for _, dir := range []string{fileCacheValuesDir, fileCacheMetadataDir} {
+		if err := os.MkdirAll(filepath.Join(c.path, dir), 0755); err != nil {
+			return fmt.Errorf("error creating cache directory: %s", err)
+		}
+	}
 
-	if err := os.WriteFile(path, []byte(value), 0766); err != nil {
+	// The value is written first so, if interrupted, a new value is left with
+	// older metadata, which at most makes it expire or revalidate earlier.
+	if err := helpers.WriteFileAtomic(c.valuePath(key), []byte(value), 0644); err != nil {
 		return fmt.Errorf("error writting cache file: %s", err)
 	}
 
+	if err := helpers.WriteFileAtomic(c.metadataPath(key), metadataJSON, 0644); err != nil {
+		return fmt.Errorf("error writting cache metadata file: %s", err)
+	}
+
 	return nil
 }
 
 func (c *FileCache) Delete(key string) error {
-	path := filepath.Join(c.path, key)
-	return os.Remove(path)
+	for _, path := range []string{c.valuePath(key), c.metadataPath(key)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *FileCache) Keys() ([]string, error) {
+	return c.listDir(fileCacheValuesDir, "")
+}
+
+func (c *FileCache) Size(key string) (int64, error) {
+	info, err := os.Stat(c.valuePath(key))
+	if err != nil {
+		return 0, models.ErrCacheKeyDontExist
+	}
+	return info.Size(), nil
+}
+
+func (c *FileCache) Namespaces() ([]string, error) {
+	return c.listDir(fileCacheNamespacesDir, "")
+}
+
+func (c *FileCache) Clear() error {
+	for _, dir := range []string{fileCacheValuesDir, fileCacheMetadataDir, fileCacheNamespacesDir} {
+		if err := os.RemoveAll(filepath.Join(c.path, dir)); err != nil {
+			return err
+		}
+	}
+	for key := range fileCacheLegacyKeys {
+		if err := os.Remove(filepath.Join(c.path, key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLegacyKeys moves the values stored by older versions to the current
+// layout, keeping their modification time as the stored time.
+func (c *FileCache) migrateLegacyKeys() {
+	for key, namespace := range fileCacheLegacyKeys {
+		legacyPath := filepath.Join(c.path, key)
+		if _, err := os.Stat(legacyPath); err != nil {
+			continue
+		}
+
+		target := c.Namespace(namespace).(*FileCache)
+		if _, err := os.Stat(target.valuePath(key)); err == nil {
+			// Already replaced by a newer value
+			if err := os.Remove(legacyPath); err != nil {
+				c.logger.Warnf("Error removing legacy cache file %s: %s", legacyPath, err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Join(target.path, fileCacheValuesDir), 0755); err != nil {
+			c.logger.Warnf("Error migrating legacy cache file %s: %s", legacyPath, err)
+			continue
+		}
+		if err := os.Rename(legacyPath, target.valuePath(key)); err != nil {
+			c.logger.Warnf("Error migrating legacy cache file %s: %s", legacyPath, err)
+		}
+	}
+}
+
+// Namespace returns a cache stored in a sub-directory of this one.
+func (c *FileCache) Namespace(name string) models.Cache {
+	return &FileCache{
+		logger: c.logger.WithField("namespace", name),
+		path:   filepath.Join(c.path, fileCacheNamespacesDir, escapeKey(name)),
+	}
 }
 
 func NewFileCache(logger *logrus.Logger) *FileCache {
@@ -65,14 +258,20 @@ func NewFileCache(logger *logrus.Logger) *FileCache {
 	if err != nil {
 		logger.Fatalf("error getting cache directory: %s", err)
 	}
-	path := filepath.Join(userCacheDir, "games-screenshot-manager")
+	return NewFileCacheWithPath(logger, filepath.Join(userCacheDir, "games-screenshot-manager"))
+}
 
+// NewFileCacheWithPath returns a file cache storing the values in the provided
+// directory.
+func NewFileCacheWithPath(logger *logrus.Logger, path string) *FileCache {
 	if err := os.MkdirAll(path, 0755); err != nil {
 		logger.Error(err)
 	}
 
-	return &FileCache{
+	cache := &FileCache{
 		logger: logger.WithField("from", "cache.file"),
 		path:   path,
 	}
+	cache.migrateLegacyKeys()
+	return cache
 }
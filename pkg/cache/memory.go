@@ -1,6 +1,9 @@
 package cache
 
 import (
+	"container/list"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -8,47 +11,207 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+type memoryCacheEntry struct {
+	key      string
+	value    string
+	metadata models.CacheMetadata
+}
+
+// MemoryCache stores values in memory. If bounded, the least recently used
+// entries are evicted once the maximum number of entries is reached.
 type MemoryCache struct {
-	logger *logrus.Entry
-	data   map[string]string
-	dataMu sync.RWMutex
+	logger     *logrus.Entry
+	maxEntries int
+
+	// data holds the list elements for each key, the list is sorted from the
+	// most to the least recently used entry.
+	data   map[string]*list.Element
+	usage  *list.List
+	dataMu sync.Mutex
 }
 
-func (c *MemoryCache) Get(key string) (result string, err error) {
-	c.dataMu.RLock()
-	defer c.dataMu.RUnlock()
+func (c *MemoryCache) get(key string, expiration time.Duration) (result string, err error) {
+	c.dataMu.Lock()
+	defer c.dataMu.Unlock()
 
-	result, exists := c.data[key]
+	element, exists := c.data[key]
 	if !exists {
 		return result, models.ErrCacheKeyDontExist
 	}
 
-	return
+	entry := element.Value.(*memoryCacheEntry)
+	if expiration > 0 && entry.metadata.StoredAt.Add(expiration).Before(time.Now()) {
+		c.remove(element)
+		return result, models.ErrCacheKeyDontExist
+	}
+
+	c.usage.MoveToFront(element)
+	return entry.value, nil
+}
+
+func (c *MemoryCache) Get(key string) (result string, err error) {
+	return c.get(key, 0)
+}
+
+func (c *MemoryCache) GetExpiry(key string, expiration time.Duration) (string, error) {
+	if expiration <= 0 {
+		// Already expired
+		c.Delete(key)
+		return "", models.ErrCacheKeyDontExist
+	}
+	return c.get(key, expiration)
+}
+
+func (c *MemoryCache) GetMetadata(key string) (models.CacheMetadata, error) {
+	c.dataMu.Lock()
+	defer c.dataMu.Unlock()
+
+	element, exists := c.data[key]
+	if !exists {
+		return models.CacheMetadata{}, models.ErrCacheKeyDontExist
+	}
+	return element.Value.(*memoryCacheEntry).metadata, nil
 }
 
 func (c *MemoryCache) Put(key, value string) error {
+	return c.PutWithMetadata(key, value, models.CacheMetadata{})
+}
+
+func (c *MemoryCache) PutWithMetadata(key, value string, metadata models.CacheMetadata) error {
+	if metadata.StoredAt.IsZero() {
+		metadata.StoredAt = time.Now()
+	}
+
 	c.dataMu.Lock()
-	c.data[key] = value
-	c.dataMu.Unlock()
+	defer c.dataMu.Unlock()
+
+	if element, exists := c.data[key]; exists {
+		entry := element.Value.(*memoryCacheEntry)
+		entry.value = value
+		entry.metadata = metadata
+		c.usage.MoveToFront(element)
+		return nil
+	}
+
+	c.data[key] = c.usage.PushFront(&memoryCacheEntry{
+		key:      key,
+		value:    value,
+		metadata: metadata,
+	})
+
+	if c.maxEntries > 0 && c.usage.Len() > c.maxEntries {
+		oldest := c.usage.Back()
+		c.logger.Debugf("Evicting %s", oldest.Value.(*memoryCacheEntry).key)
+		c.remove(oldest)
+	}
 
 	return nil
 }
 
-func (c *MemoryCache) GetExpiry(key string, expiration time.Duration) (string, error) {
-	// Since this is a in-memory storage, expiration is not required as of now.
-	return c.Get(key)
+func (c *MemoryCache) Keys() ([]string, error) {
+	return c.keys(""), nil
+}
+
+func (c *MemoryCache) Size(key string) (int64, error) {
+	c.dataMu.Lock()
+	defer c.dataMu.Unlock()
+
+	element, exists := c.data[key]
+	if !exists {
+		return 0, models.ErrCacheKeyDontExist
+	}
+	return int64(len(element.Value.(*memoryCacheEntry).value)), nil
+}
+
+// Namespace returns a view of this cache with its keys prefixed, sharing the
+// entries limit.
+func (c *MemoryCache) Namespace(name string) models.Cache {
+	return newMemoryNamespace(c, name)
+}
+
+func (c *MemoryCache) Namespaces() ([]string, error) {
+	return c.namespaces(""), nil
+}
+
+func (c *MemoryCache) Clear() error {
+	c.clear("")
+	return nil
+}
+
+// clear removes the keys stored with the prefix, including nested namespaces.
+func (c *MemoryCache) clear(prefix string) {
+	c.dataMu.Lock()
+	defer c.dataMu.Unlock()
+
+	for key, element := range c.data {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(element)
+		}
+	}
+}
+
+// keys returns the keys stored with the prefix, not in nested namespaces.
+func (c *MemoryCache) keys(prefix string) []string {
+	c.dataMu.Lock()
+	defer c.dataMu.Unlock()
+
+	var result []string
+	for key := range c.data {
+		if rest, nested, ok := splitNamespacedKey(key, prefix); ok && !nested {
+			result = append(result, rest)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// namespaces returns the namespaces nested directly under the prefix.
+func (c *MemoryCache) namespaces(prefix string) []string {
+	c.dataMu.Lock()
+	defer c.dataMu.Unlock()
+
+	seen := make(map[string]bool)
+	var result []string
+	for key := range c.data {
+		if rest, nested, ok := splitNamespacedKey(key, prefix); ok && nested {
+			name := strings.SplitN(rest, namespaceSeparator, 2)[0]
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, name)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
 }
 
 func (c *MemoryCache) Delete(key string) error {
 	c.dataMu.Lock()
-	delete(c.data, key)
-	c.dataMu.Unlock()
+	defer c.dataMu.Unlock()
+
+	if element, exists := c.data[key]; exists {
+		c.remove(element)
+	}
 	return nil
 }
 
+// remove deletes an entry, the caller must hold the lock.
+func (c *MemoryCache) remove(element *list.Element) {
+	c.usage.Remove(element)
+	delete(c.data, element.Value.(*memoryCacheEntry).key)
+}
+
 func NewMemoryCache(logger *logrus.Logger) *MemoryCache {
+	return NewBoundedMemoryCache(logger, 0)
+}
+
+// NewBoundedMemoryCache returns a memory cache holding up to maxEntries
+// entries, or unbounded if maxEntries is zero.
+func NewBoundedMemoryCache(logger *logrus.Logger, maxEntries int) *MemoryCache {
 	return &MemoryCache{
-		logger: logger.WithField("from", "cache.file"),
-		data:   make(map[string]string),
+		logger:     logger.WithField("from", "cache.memory"),
+		maxEntries: maxEntries,
+		data:       make(map[string]*list.Element),
+		usage:      list.New(),
 	}
 }
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+)
+
+// namespaceSeparator separates namespaces from keys. Since it's not a
+// printable character keys from different namespaces won't collide.
+const namespaceSeparator = "\x00"
+
+// memoryNamespace is a namespace of a memory cache, prefixing its keys.
+type memoryNamespace struct {
+	cache  *MemoryCache
+	prefix string
+}
+
+func (c *memoryNamespace) Delete(key string) error {
+	return c.cache.Delete(c.prefix + key)
+}
+
+func (c *memoryNamespace) Get(key string) (string, error) {
+	return c.cache.Get(c.prefix + key)
+}
+
+func (c *memoryNamespace) GetExpiry(key string, expiration time.Duration) (string, error) {
+	return c.cache.GetExpiry(c.prefix+key, expiration)
+}
+
+func (c *memoryNamespace) GetMetadata(key string) (models.CacheMetadata, error) {
+	return c.cache.GetMetadata(c.prefix + key)
+}
+
+func (c *memoryNamespace) Put(key, value string) error {
+	return c.cache.Put(c.prefix+key, value)
+}
+
+func (c *memoryNamespace) PutWithMetadata(key, value string, metadata models.CacheMetadata) error {
+	return c.cache.PutWithMetadata(c.prefix+key, value, metadata)
+}
+
+func (c *memoryNamespace) Keys() ([]string, error) {
+	return c.cache.keys(c.prefix), nil
+}
+
+func (c *memoryNamespace) Size(key string) (int64, error) {
+	return c.cache.Size(c.prefix + key)
+}
+
+func (c *memoryNamespace) Namespace(name string) models.Cache {
+	return newMemoryNamespace(c.cache, c.prefix+name)
+}
+
+func (c *memoryNamespace) Namespaces() ([]string, error) {
+	return c.cache.namespaces(c.prefix), nil
+}
+
+func (c *memoryNamespace) Clear() error {
+	c.cache.clear(c.prefix)
+	return nil
+}
+
+func newMemoryNamespace(cache *MemoryCache, prefix string) *memoryNamespace {
+	return &memoryNamespace{
+		cache:  cache,
+		prefix: prefix + namespaceSeparator,
+	}
+}
+
+// splitNamespacedKey returns the remainder of a key stored with the provided
+// prefix, and whether it belongs to a nested namespace.
+func splitNamespacedKey(key, prefix string) (rest string, nested bool, ok bool) {
+	if !strings.HasPrefix(key, prefix) {
+		return "", false, false
+	}
+	rest = key[len(prefix):]
+	return rest, strings.Contains(rest, namespaceSeparator), true
+}
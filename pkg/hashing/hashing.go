@@ -0,0 +1,78 @@
+package hashing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"lukechampine.com/blake3"
+)
+
+// Supported algorithms. xxHash is the fastest but isn't cryptographic, which
+// is enough to tell screenshots apart.
+const (
+	SHA256 = "sha256"
+	BLAKE3 = "blake3"
+	XXHash = "xxhash"
+)
+
+const Default = SHA256
+
+var algorithms = map[string]func() hash.Hash{
+	SHA256: sha256.New,
+	BLAKE3: func() hash.Hash { return blake3.New(32, nil) },
+	XXHash: func() hash.Hash { return xxhash.New() },
+}
+
+// Algorithms returns the names of the supported algorithms.
+func Algorithms() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type hasher struct {
+	name string
+	new  func() hash.Hash
+}
+
+func (h *hasher) Name() string {
+	return h.name
+}
+
+func (h *hasher) Hash(r io.Reader) (string, error) {
+	digest := h.new()
+	if _, err := io.Copy(digest, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// HashFile returns the hash of the contents of the file.
+func HashFile(hasher models.Hasher, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return hasher.Hash(f)
+}
+
+// NewHasher returns the hasher for the algorithm.
+func NewHasher(name string) (models.Hasher, error) {
+	newHash, exists := algorithms[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown hash algorithm %s", name)
+	}
+	return &hasher{name: name, new: newHash}, nil
+}
@@ -0,0 +1,75 @@
+package hashing_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+)
+
+// TestHashers
+// Tests that the hashers return the known hashes of a string
+func TestHashers(t *testing.T) {
+	expected := map[string]string{
+		hashing.SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		hashing.BLAKE3: "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85",
+		hashing.XXHash: "44bc2cf5ad770999",
+	}
+
+	for _, name := range hashing.Algorithms() {
+		hasher, err := hashing.NewHasher(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := hasher.Hash(strings.NewReader("abc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash != expected[name] {
+			t.Errorf("Wrong %s hash: %s (should be %s)", name, hash, expected[name])
+		}
+	}
+
+	if _, err := hashing.NewHasher("md4"); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+}
+
+func testImage(seed int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 320, 180))
+	for y := 0; y < 180; y++ {
+		for x := 0; x < 320; x++ {
+			v := uint8((x*seed + y*(7-seed)) % 256)
+			img.Set(x, y, color.RGBA{v, 255 - v, uint8(x + y), 255})
+		}
+	}
+	return img
+}
+
+// TestDHash
+// Tests that the same image stored as PNG and JPEG has close perceptual
+// hashes, and different images don't
+func TestDHash(t *testing.T) {
+	var pngBuffer, jpegBuffer bytes.Buffer
+	if err := png.Encode(&pngBuffer, testImage(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegBuffer, testImage(1), &jpeg.Options{Quality: 75}); err != nil {
+		t.Fatal(err)
+	}
+
+	pngImage, _ := png.Decode(&pngBuffer)
+	jpegImage, _ := jpeg.Decode(&jpegBuffer)
+
+	if distance := hashing.Distance(hashing.DHash(pngImage), hashing.DHash(jpegImage)); distance > hashing.DefaultDistance {
+		t.Errorf("PNG and JPEG of the same image at distance %d", distance)
+	}
+	if distance := hashing.Distance(hashing.DHash(pngImage), hashing.DHash(testImage(5))); distance <= hashing.DefaultDistance {
+		t.Errorf("Different images at distance %d", distance)
+	}
+}
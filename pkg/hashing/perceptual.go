@@ -0,0 +1,99 @@
+package hashing
+
+import (
+	"image"
+	"math/bits"
+	"os"
+
+	// Formats screenshots are stored in
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// DefaultDistance is the maximum distance between the perceptual hashes of
+// images considered the same.
+const DefaultDistance = 5
+
+// dHashSamples is the number of pixels sampled in each direction of a cell,
+// enough for a stable average without reading every pixel of large images.
+const dHashSamples = 16
+
+// DHash returns the difference hash of the image: it's scaled down to 9x8
+// grayscale cells, and each bit tells if a cell is brighter than the next one
+// in its row. Images that look the same have hashes at a small distance, even
+// if they are stored in different formats or sizes.
+func DHash(img image.Image) uint64 {
+	const width, height = 9, 8
+	bounds := img.Bounds()
+
+	var cells [height][width]uint64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := image.Rect(
+				bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height,
+				bounds.Min.X+(x+1)*bounds.Dx()/width, bounds.Min.Y+(y+1)*bounds.Dy()/height,
+			)
+			cells[y][x] = averageLuminance(img, cell)
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width-1; x++ {
+			hash <<= 1
+			if cells[y][x] > cells[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// averageLuminance returns the average luminance of the pixels sampled in the
+// rectangle.
+func averageLuminance(img image.Image, rect image.Rectangle) uint64 {
+	if rect.Empty() {
+		return 0
+	}
+
+	stepX, stepY := rect.Dx()/dHashSamples, rect.Dy()/dHashSamples
+	if stepX < 1 {
+		stepX = 1
+	}
+	if stepY < 1 {
+		stepY = 1
+	}
+
+	var total, count uint64
+	for y := rect.Min.Y; y < rect.Max.Y; y += stepY {
+		for x := rect.Min.X; x < rect.Max.X; x += stepX {
+			r, g, b, _ := img.At(x, y).RGBA()
+			total += (19595*uint64(r) + 38470*uint64(g) + 7471*uint64(b) + 1<<15) >> 16
+			count++
+		}
+	}
+	return total / count
+}
+
+// Distance returns the number of bits that differ between two perceptual
+// hashes.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// DHashFile returns the difference hash of an image file, along with its
+// format and size.
+func DHashFile(path string) (hash uint64, format string, size image.Point, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", image.Point{}, err
+	}
+	defer f.Close()
+
+	img, format, err := image.Decode(f)
+	if err != nil {
+		return 0, "", image.Point{}, err
+	}
+	return DHash(img), format, img.Bounds().Size(), nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !openbsd && !dragonfly && !solaris && !darwin && !freebsd && !netbsd && !windows
+
+package helpers
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime returns the modification time of the file, as its last access
+// time isn't available.
+func accessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
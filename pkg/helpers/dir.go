@@ -1,9 +1,13 @@
 package helpers
 
 import (
+	"fmt"
+	"os"
 	"os/user"
 	"path/filepath"
 	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
 )
 
 func ExpandUser(providedPath string) string {
@@ -18,3 +22,54 @@ func ExpandUser(providedPath string) string {
 	}
 	return path
 }
+
+// FirstExistingPath returns the first of the paths that exists, expanded.
+func FirstExistingPath(paths ...string) (string, error) {
+	for _, path := range paths {
+		path = ExpandUser(path)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("none of the paths exist: %s", strings.Join(paths, ", "))
+}
+
+// DetectInputPath checks that the input path required by a provider is set
+// and exists, returning it expanded.
+func DetectInputPath(options models.ProviderOptions) (string, error) {
+	if options.InputPath == "" {
+		return "", models.ErrInputPathRequired
+	}
+	return FirstExistingPath(options.InputPath)
+}
+
+// MkdirAll creates the directory and its missing parents with the provided
+// mode, regardless of the umask, owned by owner unless it's nil.
+func MkdirAll(path string, mode os.FileMode, owner *models.Owner) error {
+	var missing []string
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(path, mode); err != nil {
+		return err
+	}
+
+	for _, dir := range missing {
+		if err := os.Chmod(dir, mode); err != nil {
+			return err
+		}
+		if owner != nil {
+			if err := os.Chown(dir, owner.UID, owner.GID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -1,16 +1,45 @@
 package helpers
 
 import (
+	"context"
 	"crypto/md5"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
 )
 
 var ErrCopyFileDestinationExists = errors.New("copy destination exists")
 
-func CopyFile(src, dst string) (int64, error) {
+// Default modes for copied files and created directories.
+const (
+	DefaultFileMode os.FileMode = 0644
+	DefaultDirMode  os.FileMode = 0755
+)
+
+// CopyOptions changes how CopyFile copies files.
+type CopyOptions struct {
+	// Limiter throttles the copy, unless it's nil.
+	Limiter *RateLimiter
+	// Progress is called with the number of bytes copied after each read.
+	Progress func(n int64)
+	// Mode of the copy, DefaultFileMode if zero.
+	Mode os.FileMode
+	// PreserveTimes sets the access and modification times of the source on
+	// the copy.
+	PreserveTimes bool
+	// Owner of the copy, unchanged if nil.
+	Owner *models.Owner
+}
+
+// CopyFile copies src to dst, which must not exist. The contents are written
+// to a temporary file moved to dst once complete, so an interrupted copy
+// doesn't leave a truncated file behind, without replacing a file created in
+// the meantime. The copy stops if ctx is cancelled.
+func CopyFile(ctx context.Context, src, dst string, options CopyOptions) (int64, error) {
 	sourceFileStat, err := os.Stat(src)
 	if err != nil {
 		return 0, err
@@ -31,13 +60,81 @@ func CopyFile(src, dst string) (int64, error) {
 		return 0, ErrCopyFileDestinationExists
 	}
 
-	destination, err := os.Create(dst)
+	destination, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-*")
 	if err != nil {
 		return 0, err
 	}
-	defer destination.Close()
-	nBytes, err := io.Copy(destination, source)
-	return nBytes, err
+
+	nBytes, err := io.Copy(destination, &progressReader{
+		reader:   options.Limiter.Reader(ctx, source),
+		progress: options.Progress,
+	})
+	if err == nil {
+		err = setFileAttributes(destination, options)
+	}
+	if closeErr := destination.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && options.PreserveTimes {
+		err = os.Chtimes(destination.Name(), accessTime(sourceFileStat), sourceFileStat.ModTime())
+	}
+	if err == nil {
+		err = moveNoReplace(destination.Name(), dst)
+	}
+	if err != nil {
+		os.Remove(destination.Name())
+		return 0, err
+	}
+	return nBytes, nil
+}
+
+// moveNoReplace moves src to dst unless dst exists, by linking it and removing
+// src. Filesystems without hard links fall back to a rename, which may replace
+// a file created since dst was checked.
+func moveNoReplace(src, dst string) error {
+	err := os.Link(src, dst)
+	switch {
+	case err == nil:
+		// The copy is already in place, at worst a hidden file is left behind
+		os.Remove(src)
+		return nil
+	case os.IsExist(err):
+		return ErrCopyFileDestinationExists
+	}
+
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		return ErrCopyFileDestinationExists
+	}
+	return os.Rename(src, dst)
+}
+
+// setFileAttributes sets the mode and owner of the copy.
+func setFileAttributes(file *os.File, options CopyOptions) error {
+	mode := options.Mode
+	if mode == 0 {
+		mode = DefaultFileMode
+	}
+	if err := file.Chmod(mode); err != nil {
+		return err
+	}
+	if options.Owner != nil {
+		return file.Chown(options.Owner.UID, options.Owner.GID)
+	}
+	return nil
+}
+
+// progressReader calls progress with the number of bytes of each read.
+type progressReader struct {
+	reader   io.Reader
+	progress func(n int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 && r.progress != nil {
+		r.progress(int64(n))
+	}
+	return n, err
 }
 
 func Md5File(src string) ([]byte, error) {
@@ -54,3 +151,31 @@ func Md5File(src string) ([]byte, error) {
 
 	return h.Sum(nil), nil
 }
+
+// WriteFileAtomic writes data to a temporary file in the same directory and
+// renames it to path, so path is never left with partial contents.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	tmpfile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmpfile.Name())
+		}
+	}()
+
+	if _, err := tmpfile.Write(data); err != nil {
+		tmpfile.Close()
+		return err
+	}
+	if err := tmpfile.Chmod(perm); err != nil {
+		tmpfile.Close()
+		return err
+	}
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpfile.Name(), path)
+}
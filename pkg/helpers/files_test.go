@@ -2,9 +2,13 @@ package helpers_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
 )
@@ -35,7 +39,7 @@ func TestCopyFileOk(t *testing.T) {
 	}
 	os.Remove(tmpfileDest.Name())
 
-	bytesCopied, err := helpers.CopyFile(tmpfile.Name(), tmpfileDest.Name())
+	bytesCopied, err := helpers.CopyFile(context.Background(), tmpfile.Name(), tmpfileDest.Name(), helpers.CopyOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -82,3 +86,59 @@ func TestMD5FileOk(t *testing.T) {
 		t.Errorf("MD5 Missmatch: %s (should be %s)", string(resultMD5), testfileMD5)
 	}
 }
+
+// TestCopyFileCancelled
+// Tests that a cancelled copy doesn't leave the destination or temporary
+// files behind
+func TestCopyFileCancelled(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source")
+	if err := os.WriteFile(src, []byte(testfileContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := helpers.CopyFile(ctx, src, filepath.Join(dir, "destination"), helpers.CopyOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancelled error, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the source file, got %d files", len(entries))
+	}
+}
+
+// TestCopyFileAttributes
+// Tests that the copy has the provided mode and the times of the source
+func TestCopyFileAttributes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source")
+	if err := os.WriteFile(src, []byte(testfileContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(src, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "destination")
+	if _, err := helpers.CopyFile(context.Background(), src, dst, helpers.CopyOptions{Mode: 0600, PreserveTimes: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("Modification time not preserved: %s (should be %s)", info.ModTime(), modTime)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Wrong mode: %s (should be %s)", info.Mode().Perm(), os.FileMode(0600))
+	}
+}
@@ -1,48 +1,165 @@
 package helpers
 
 import (
-	"io/ioutil"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"time"
 )
 
-func DoRequest(method string, requestURL string) (*http.Response, error) {
-	parsedURL, _ := url.Parse(requestURL)
-	request := http.Request{
-		Method: method,
-		URL:    parsedURL,
-		Header: map[string][]string{
-			"User-Agent": {"github.com/fmartingr/games-screenshot-manager"},
-		},
-		ProtoMajor: 2,
-		ProtoMinor: 1,
-	}
-	return http.DefaultClient.Do(&request)
+const userAgent = "github.com/fmartingr/games-screenshot-manager"
+
+// maxRetryAfter limits the wait requested by servers before retrying.
+const maxRetryAfter = time.Minute
+
+// ErrOffline is returned instead of making requests in offline mode.
+var ErrOffline = errors.New("offline mode enabled")
+
+// HTTPOptions configures the HTTP client used by the application.
+type HTTPOptions struct {
+	// Timeout for each request attempt, including reading the body.
+	Timeout time.Duration
+	// Retries for requests failing with a 5xx or 429 status code, or a
+	// network error.
+	Retries int
+	// RetryBackoff is the wait before the first retry, doubled on each one.
+	RetryBackoff time.Duration
+	// Proxy URL, the environment (HTTP_PROXY, HTTPS_PROXY...) is used if empty.
+	Proxy     string
+	UserAgent string
+	// Offline disables all requests.
+	Offline bool
+}
+
+// DefaultHTTPOptions are the options used unless the client is replaced.
+var DefaultHTTPOptions = HTTPOptions{
+	Timeout:      30 * time.Second,
+	Retries:      3,
+	RetryBackoff: time.Second,
+	UserAgent:    userAgent,
+}
+
+// HTTPClient makes requests with timeouts and retries.
+type HTTPClient struct {
+	client  *http.Client
+	options HTTPOptions
 }
 
-func DownloadURLIntoTempFile(requestURL string) (string, error) {
-	response, err := DoRequest("GET", requestURL)
+// NewRequest returns a request with the headers common to all requests made by
+// the application.
+func (c *HTTPClient) NewRequest(ctx context.Context, method string, requestURL string) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("error creating request: %s", err)
 	}
+	request.Header.Set("User-Agent", c.options.UserAgent)
+	return request, nil
+}
 
-	if response.Body != nil {
-		defer response.Body.Close()
+// retryAfter returns the wait before retrying a request, honouring the
+// Retry-After header of the response if any.
+func (c *HTTPClient) retryAfter(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			if wait := time.Duration(seconds) * time.Second; wait < maxRetryAfter {
+				return wait
+			}
+			return maxRetryAfter
+		}
 	}
+	return c.options.RetryBackoff << attempt
+}
 
-	tmpfile, err := ioutil.TempFile("", "games-screenshot-manager")
-	if err != nil {
-		return "", err
+func shouldRetry(response *http.Response) bool {
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+}
+
+// Do sends the request, retrying it with backoff on network errors and 5xx or
+// 429 responses. Requests must not have a body.
+func (c *HTTPClient) Do(request *http.Request) (*http.Response, error) {
+	if c.options.Offline {
+		return nil, fmt.Errorf("%w: %s", ErrOffline, request.URL)
 	}
 
-	defer tmpfile.Close()
+	ctx := request.Context()
+	for attempt := 0; ; attempt++ {
+		response, err := c.client.Do(request)
+		if attempt >= c.options.Retries || ctx.Err() != nil || (err == nil && !shouldRetry(response)) {
+			return response, err
+		}
 
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return "", err
+		wait := c.retryAfter(attempt, response)
+		if response != nil {
+			response.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
 	}
+}
 
-	tmpfile.Write(body)
+// NewHTTPClient returns a client for the provided options.
+func NewHTTPClient(options HTTPOptions) (*HTTPClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if options.Proxy != "" {
+		proxyURL, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing proxy URL: %s", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if options.UserAgent == "" {
+		options.UserAgent = userAgent
+	}
+
+	return &HTTPClient{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   options.Timeout,
+		},
+		options: options,
+	}, nil
+}
+
+var (
+	httpClient   *HTTPClient
+	httpClientMu sync.RWMutex
+)
 
-	return tmpfile.Name(), nil
+func init() {
+	httpClient, _ = NewHTTPClient(DefaultHTTPOptions)
+}
+
+// HTTP returns the client shared by the application.
+func HTTP() *HTTPClient {
+	httpClientMu.RLock()
+	defer httpClientMu.RUnlock()
+	return httpClient
+}
+
+// SetHTTPClient replaces the client shared by the application.
+func SetHTTPClient(client *HTTPClient) {
+	httpClientMu.Lock()
+	defer httpClientMu.Unlock()
+	httpClient = client
+}
+
+// DoRequest makes a request using the shared client.
+func DoRequest(ctx context.Context, method string, requestURL string) (*http.Response, error) {
+	client := HTTP()
+	request, err := client.NewRequest(ctx, method, requestURL)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(request)
 }
@@ -0,0 +1,80 @@
+package helpers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+func newTestHTTPClient(t *testing.T, options helpers.HTTPOptions) *helpers.HTTPClient {
+	client, err := helpers.NewHTTPClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// TestHTTPClientRetries
+// Tests that server errors and rate limits are retried, but not client errors
+func TestHTTPClientRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case requests == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case requests == 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	client := newTestHTTPClient(t, helpers.HTTPOptions{Retries: 3, RetryBackoff: time.Millisecond})
+
+	request, _ := client.NewRequest(context.Background(), "GET", server.URL)
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || requests != 3 {
+		t.Errorf("Got status %d after %d requests (should be 200 after 3)", response.StatusCode, requests)
+	}
+
+	requests = 0
+	request, _ = client.NewRequest(context.Background(), "GET", server.URL+"/missing")
+	response, err = client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if requests != 1 {
+		t.Errorf("Client errors should not be retried, got %d requests", requests)
+	}
+}
+
+// TestHTTPClientOffline
+// Tests that no requests are made in offline mode
+func TestHTTPClientOffline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request made in offline mode")
+	}))
+	defer server.Close()
+
+	client := newTestHTTPClient(t, helpers.HTTPOptions{Offline: true})
+
+	request, _ := client.NewRequest(context.Background(), "GET", server.URL)
+	if _, err := client.Do(request); !errors.Is(err, helpers.ErrOffline) {
+		t.Errorf("Expected offline error, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// rateLimitChunk is the most read at once by limited readers, so the
+// throughput is smooth even with large buffers.
+const rateLimitChunk = 32 * 1024
+
+// RateLimiter limits the throughput shared by a set of readers, for slow
+// destinations like USB drives or network shares. A nil RateLimiter doesn't
+// limit anything.
+type RateLimiter struct {
+	bytesPerSecond int64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// Reader returns a reader for r limited by the rate limiter, that stops
+// reading once ctx is cancelled.
+func (l *RateLimiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, reader: r, limiter: l}
+}
+
+// wait blocks until n more bytes can be read.
+func (l *RateLimiter) wait(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.bytesPerSecond))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// NewRateLimiter returns a rate limiter for the provided bytes per second, or
+// nil if it isn't positive.
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &RateLimiter{bytesPerSecond: bytesPerSecond}
+}
+
+// contextReader stops reading once its context is cancelled, throttled by its
+// rate limiter if any.
+type contextReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *RateLimiter
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if r.limiter == nil {
+		return r.reader.Read(p)
+	}
+
+	if len(p) > rateLimitChunk {
+		p = p[:rateLimitChunk]
+	}
+	n, err := r.reader.Read(p)
+	if waitErr := r.limiter.wait(r.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
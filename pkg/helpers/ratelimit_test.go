@@ -0,0 +1,31 @@
+package helpers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+// TestRateLimiter
+// Tests that readers are throttled to the rate of the limiter
+func TestRateLimiter(t *testing.T) {
+	limiter := helpers.NewRateLimiter(256 * 1024)
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, limiter.Reader(context.Background(), bytes.NewReader(make([]byte, 128*1024))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 128*1024 {
+		t.Errorf("Read %d bytes (should be %d)", n, 128*1024)
+	}
+
+	// The first chunk is read right away
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Reading 128 KiB at 256 KiB/s took %s", elapsed)
+	}
+}
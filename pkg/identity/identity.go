@@ -0,0 +1,216 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+var symbolsReplacer = strings.NewReplacer("™", "", "®", "", "©", "")
+
+// editions are the edition names (as keys) ignored when comparing names.
+var editions = [][]string{
+	{"game", "of", "the", "year"},
+	{"digital", "deluxe"},
+	{"collector", "s"},
+	{"collectors"},
+	{"goty"},
+	{"definitive"},
+	{"deluxe"},
+	{"complete"},
+	{"ultimate"},
+	{"gold"},
+	{"premium"},
+	{"standard"},
+	{"anniversary"},
+	{"enhanced"},
+	{"special"},
+}
+
+// Clean removes trademark symbols and extra whitespace from a game name.
+func Clean(name string) string {
+	return strings.Join(strings.Fields(symbolsReplacer.Replace(name)), " ")
+}
+
+// Key returns the identity key for a game name: names that only differ in
+// case, punctuation, trademark symbols or edition have the same key.
+func Key(name string) string {
+	name = strings.ToLower(symbolsReplacer.Replace(name))
+	name = strings.ReplaceAll(name, "&", " and ")
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	if stripped := stripEdition(words); len(stripped) > 0 {
+		words = stripped
+	}
+
+	return strings.Join(words, " ")
+}
+
+func stripEdition(words []string) []string {
+	if len(words) > 0 && words[len(words)-1] == "goty" {
+		return words[:len(words)-1]
+	}
+
+	if len(words) == 0 || words[len(words)-1] != "edition" {
+		return words
+	}
+	words = words[:len(words)-1]
+
+	for _, edition := range editions {
+		if len(words) < len(edition) {
+			continue
+		}
+		if strings.Join(words[len(words)-len(edition):], " ") == strings.Join(edition, " ") {
+			return words[:len(words)-len(edition)]
+		}
+	}
+
+	return words
+}
+
+// Resolver sets the canonical name on games, using the user provided aliases
+// when available.
+type Resolver struct {
+	// aliases maps `<provider>:<game id>` and name keys to canonical names
+	aliases map[string]string
+	// providers maps the providers used in aliases to those aliases
+	providers map[string][]string
+}
+
+// aliasKey returns the key for a game by provider and ID.
+func aliasKey(provider, id string) string {
+	return provider + ":" + id
+}
+
+// GameAlias returns the alias identifying a game by its provider and ID.
+func GameAlias(game *models.Game) string {
+	return aliasKey(game.Provider, game.ID)
+}
+
+// Add maps a game, as `<provider>:<game id>` or by name, to a canonical name.
+func (r *Resolver) Add(alias, canonical string) {
+	if provider, id, found := strings.Cut(alias, ":"); found && provider != "" {
+		r.aliases[aliasKey(provider, id)] = canonical
+		r.providers[provider] = append(r.providers[provider], alias)
+	}
+	r.aliases[Key(alias)] = canonical
+}
+
+// UnknownAliases returns the `<provider>:<game id>` aliases whose provider
+// is not one of the given ones, sorted.
+func (r *Resolver) UnknownAliases(providers []string) []string {
+	known := make(map[string]bool, len(providers))
+	for _, provider := range providers {
+		known[provider] = true
+	}
+
+	var result []string
+	for provider, aliases := range r.providers {
+		if !known[provider] {
+			result = append(result, aliases...)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// LoadFile reads the aliases from a JSON file mapping aliases to canonical
+// names, like: {"steam:1245620": "Elden Ring", "ELDEN RING": "Elden Ring"}
+func (r *Resolver) LoadFile(path string) error {
+	contents, err := os.ReadFile(helpers.ExpandUser(path))
+	if err != nil {
+		return fmt.Errorf("error reading aliases file: %w", err)
+	}
+
+	var aliases map[string]string
+	if err := json.Unmarshal(contents, &aliases); err != nil {
+		return fmt.Errorf("error parsing aliases file: %s", err)
+	}
+
+	for alias, canonical := range aliases {
+		r.Add(alias, canonical)
+	}
+
+	return nil
+}
+
+// Name returns the canonical name for a game.
+func (r *Resolver) Name(game *models.Game) string {
+	if canonical, exists := r.aliases[aliasKey(game.Provider, game.ID)]; exists {
+		return canonical
+	}
+	if canonical, exists := r.aliases[Key(game.Name)]; exists && game.Name != "" {
+		return canonical
+	}
+	return Clean(game.Name)
+}
+
+// ResolveGames sets the canonical name on all the provided games.
+func (r *Resolver) ResolveGames(games []*models.Game) {
+	for _, game := range games {
+		game.Name = r.Name(game)
+	}
+}
+
+// Unresolved returns the games without a name.
+func Unresolved(games []*models.Game) []*models.Game {
+	var result []*models.Game
+	for _, game := range games {
+		if game.Name == "" {
+			result = append(result, game)
+		}
+	}
+	return result
+}
+
+// SaveAliases adds the provided aliases to the aliases file, creating it if
+// it doesn't exist. Existing aliases are kept unless overridden.
+func SaveAliases(path string, aliases map[string]string) error {
+	path = helpers.ExpandUser(path)
+	existing := make(map[string]string)
+
+	contents, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(contents, &existing); err != nil {
+			return fmt.Errorf("error parsing aliases file: %s", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("error reading aliases file: %s", err)
+	}
+
+	for alias, canonical := range aliases {
+		existing[alias] = canonical
+	}
+
+	contents, err = json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding aliases: %s", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating aliases file directory: %s", err)
+	}
+
+	if err := os.WriteFile(path, contents, 0644); err != nil {
+		return fmt.Errorf("error writing aliases file: %s", err)
+	}
+
+	return nil
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{
+		aliases:   make(map[string]string),
+		providers: make(map[string][]string),
+	}
+}
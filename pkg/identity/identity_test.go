@@ -0,0 +1,69 @@
+package identity_test
+
+import (
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/identity"
+)
+
+// TestKey
+// Tests that names differing in case, punctuation, symbols or edition share
+// the same key
+func TestKey(t *testing.T) {
+	for _, names := range [][]string{
+		{"ELDEN RING™", "Elden Ring", "Elden Ring Deluxe Edition"},
+		{"The Witcher® 3: Wild Hunt", "The Witcher 3 Wild Hunt - Game of the Year Edition", "the witcher 3: wild hunt GOTY"},
+		{"Ratchet & Clank", "Ratchet and Clank"},
+		{"Edition", "edition"},
+	} {
+		for _, name := range names[1:] {
+			if identity.Key(name) != identity.Key(names[0]) {
+				t.Errorf("Key(%q) = %q (should be %q)", name, identity.Key(name), identity.Key(names[0]))
+			}
+		}
+	}
+
+	if identity.Key("Minecraft (Java)") == identity.Key("Minecraft (Bedrock)") {
+		t.Error("Different variants should not share the same key")
+	}
+}
+
+// TestResolverName
+// Tests that aliases by provider ID take precedence over aliases by name and
+// that names are cleaned otherwise
+func TestResolverName(t *testing.T) {
+	resolver := identity.NewResolver()
+	resolver.Add("steam:1245620", "Elden Ring")
+	resolver.Add("ELDEN RING", "Elden Ring (PS)")
+	resolver.Add("playstation-4:CUSA00900", "Bloodborne")
+
+	for _, test := range []struct {
+		game     models.Game
+		expected string
+	}{
+		{models.NewGame("1245620", "", "PC", "steam"), "Elden Ring"},
+		{models.NewGame("ELDEN RING™", "ELDEN RING™", "PlayStation 5", "playstation-5"), "Elden Ring (PS)"},
+		{models.NewGame("570", "Dota  2™", "PC", "steam"), "Dota 2"},
+		{models.NewGame("CUSA00900", "", "PlayStation 4", "playstation-4"), "Bloodborne"},
+	} {
+		if result := resolver.Name(&test.game); result != test.expected {
+			t.Errorf("Name(%s:%s) = %q (should be %q)", test.game.Provider, test.game.ID, result, test.expected)
+		}
+	}
+}
+
+// TestResolverUnknownAliases
+// Tests that aliases by provider and ID are reported when their provider is
+// not registered
+func TestResolverUnknownAliases(t *testing.T) {
+	resolver := identity.NewResolver()
+	resolver.Add("steam:1245620", "Elden Ring")
+	resolver.Add("PlayStation 4:CUSA00900", "Bloodborne")
+	resolver.Add("ELDEN RING", "Elden Ring")
+
+	unknown := resolver.UnknownAliases([]string{"steam", "playstation-4"})
+	if len(unknown) != 1 || unknown[0] != "PlayStation 4:CUSA00900" {
+		t.Errorf("expected the PlayStation 4 alias to be unknown, got %v", unknown)
+	}
+}
@@ -0,0 +1,347 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/sirupsen/logrus"
+)
+
+// IndexFileName is the name of the index in the output folder. As it's
+// hidden it's ignored when walking the library.
+const IndexFileName = ".gsm-index.json"
+
+// Entry is a file in the library. Hashes are only computed when needed, when
+// another file of the same size is compared to it.
+type Entry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Hash    string    `json:"hash,omitempty"`
+
+	// source is the file being copied into the entry path, hashed instead of
+	// the entry until the copy is committed.
+	source string
+}
+
+type indexFile struct {
+	Algorithm string            `json:"algorithm"`
+	Entries   map[string]*Entry `json:"entries"`
+}
+
+// Index keeps the size and hash of the files in the output folder, so
+// screenshots can be checked against the whole library, not only the file
+// with the same name. Paths are relative to the output folder.
+type Index struct {
+	logger *logrus.Entry
+	root   string
+	hasher models.Hasher
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+	bySize  map[int64]map[string]bool
+}
+
+// ErrReserved is returned when reserving a destination already in the
+// library or reserved by another copy.
+var ErrReserved = errors.New("destination already reserved")
+
+// Find returns the path of a file in the library with the same contents as
+// source, if any.
+func (i *Index) Find(source string) (string, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", err
+	}
+
+	_, duplicate, err := i.find(source, i.candidates(info.Size()))
+	return duplicate, err
+}
+
+// Reserve looks for a file in the library with the same contents as source,
+// returning its path. If there's none the destination is reserved for
+// source, so identical screenshots copied at the same time are detected too.
+// Reservations must be committed or released once the copy is done.
+func (i *Index) Reserve(source, destination string) (string, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", err
+	}
+	path := i.relative(destination)
+
+	// Only files of the same size need to be hashed
+	i.mu.Lock()
+	if _, exists := i.entries[path]; exists {
+		i.mu.Unlock()
+		return "", ErrReserved
+	}
+	candidates := i.sameSize(info.Size())
+	if len(candidates) == 0 {
+		i.add(path, &Entry{Size: info.Size(), source: source})
+	}
+	i.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	sourceHash, duplicate, err := i.find(source, candidates)
+	if err != nil || duplicate != "" {
+		return duplicate, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, exists := i.entries[path]; exists {
+		return "", ErrReserved
+	}
+	// Files reserved while hashing already have their hash
+	for candidate := range i.bySize[info.Size()] {
+		if i.entries[candidate].Hash == sourceHash {
+			return filepath.Join(i.root, filepath.FromSlash(candidate)), nil
+		}
+	}
+	i.add(path, &Entry{Size: info.Size(), Hash: sourceHash, source: source})
+	return "", nil
+}
+
+// candidates returns the files in the library with the provided size.
+func (i *Index) candidates(size int64) []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.sameSize(size)
+}
+
+// sameSize returns the files in the library with the provided size, the lock
+// must be held.
+func (i *Index) sameSize(size int64) []string {
+	candidates := make([]string, 0, len(i.bySize[size]))
+	for path := range i.bySize[size] {
+		candidates = append(candidates, path)
+	}
+	return candidates
+}
+
+// find looks for the candidate with the same contents as source, returning
+// the hash of source and the path of the candidate. Source is only hashed if
+// there are candidates.
+func (i *Index) find(source string, candidates []string) (string, string, error) {
+	if len(candidates) == 0 {
+		return "", "", nil
+	}
+
+	sourceHash, err := hashing.HashFile(i.hasher, source)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, candidate := range candidates {
+		candidateHash, err := i.hash(candidate)
+		if err != nil {
+			i.logger.Debugf("Can't hash %s: %s", candidate, err)
+			continue
+		}
+		if candidateHash == sourceHash {
+			return sourceHash, filepath.Join(i.root, filepath.FromSlash(candidate)), nil
+		}
+	}
+
+	return sourceHash, "", nil
+}
+
+// Commit records the file copied to a reserved destination.
+func (i *Index) Commit(destination string) {
+	path := i.relative(destination)
+	info, err := os.Stat(destination)
+	if err != nil {
+		i.remove(path)
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if entry, exists := i.entries[path]; exists {
+		entry.ModTime = info.ModTime()
+		entry.source = ""
+	}
+}
+
+// Release removes the reservation of a destination whose copy failed.
+func (i *Index) Release(destination string) {
+	i.remove(i.relative(destination))
+}
+
+// Hash returns the hash of a file in the library, computing it only if it
+// changed since it was indexed.
+func (i *Index) Hash(path string) (string, error) {
+	return i.hash(i.relative(path))
+}
+
+func (i *Index) hash(path string) (string, error) {
+	i.mu.Lock()
+	entry, exists := i.entries[path]
+	var hash, source string
+	if exists {
+		hash, source = entry.Hash, entry.source
+	}
+	i.mu.Unlock()
+
+	if hash != "" {
+		return hash, nil
+	}
+
+	file := source
+	if file == "" {
+		file = filepath.Join(i.root, filepath.FromSlash(path))
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		entry = &Entry{Size: info.Size(), ModTime: info.ModTime()}
+	}
+
+	hash, err := hashing.HashFile(i.hasher, file)
+	if err != nil {
+		return "", err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	current, exists := i.entries[path]
+	if exists && current.source == source {
+		current.Hash = hash
+	} else if !exists && source == "" {
+		entry.Hash = hash
+		i.add(path, entry)
+	}
+	return hash, nil
+}
+
+// add stores the entry, the lock must be held.
+func (i *Index) add(path string, entry *Entry) {
+	if previous, exists := i.entries[path]; exists {
+		delete(i.bySize[previous.Size], path)
+	}
+	i.entries[path] = entry
+	if i.bySize[entry.Size] == nil {
+		i.bySize[entry.Size] = make(map[string]bool)
+	}
+	i.bySize[entry.Size][path] = true
+}
+
+func (i *Index) remove(path string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if entry, exists := i.entries[path]; exists {
+		delete(i.bySize[entry.Size], path)
+		delete(i.entries, path)
+	}
+}
+
+func (i *Index) relative(path string) string {
+	if relative, err := filepath.Rel(i.root, path); err == nil {
+		return filepath.ToSlash(relative)
+	}
+	return filepath.ToSlash(path)
+}
+
+// scan indexes the files in the output folder, keeping the hashes of the
+// ones that didn't change since they were indexed.
+func (i *Index) scan(previous map[string]*Entry) error {
+	err := filepath.WalkDir(i.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != i.root {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		relative := i.relative(path)
+		entry := &Entry{Size: info.Size(), ModTime: info.ModTime()}
+		if old, exists := previous[relative]; exists && old.Size == entry.Size && old.ModTime.Equal(entry.ModTime) {
+			entry.Hash = old.Hash
+		}
+		i.add(relative, entry)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Save stores the index in the output folder.
+func (i *Index) Save() error {
+	i.mu.Lock()
+	entries := make(map[string]*Entry, len(i.entries))
+	for path, entry := range i.entries {
+		// Reservations that weren't committed aren't in the library
+		if entry.source == "" {
+			entries[path] = entry
+		}
+	}
+	i.mu.Unlock()
+
+	contents, err := json.Marshal(indexFile{Algorithm: i.hasher.Name(), Entries: entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(i.root, 0755); err != nil {
+		return err
+	}
+	return helpers.WriteFileAtomic(filepath.Join(i.root, IndexFileName), contents, 0644)
+}
+
+// OpenIndex returns the index of the output folder, updated with the files
+// added, changed or removed since it was saved. Hashes computed with another
+// algorithm are discarded.
+func OpenIndex(logger *logrus.Logger, outputPath string, hasher models.Hasher) (*Index, error) {
+	index := &Index{
+		logger:  logger.WithField("from", "library"),
+		root:    helpers.ExpandUser(outputPath),
+		hasher:  hasher,
+		entries: make(map[string]*Entry),
+		bySize:  make(map[int64]map[string]bool),
+	}
+
+	var saved indexFile
+	contents, err := os.ReadFile(filepath.Join(index.root, IndexFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading index: %s", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(contents, &saved); err != nil {
+			index.logger.Warnf("Invalid index, rebuilding it: %s", err)
+		}
+	}
+	if saved.Algorithm != hasher.Name() {
+		for _, entry := range saved.Entries {
+			entry.Hash = ""
+		}
+	}
+
+	if err := index.scan(saved.Entries); err != nil {
+		return nil, fmt.Errorf("error scanning %s: %s", index.root, err)
+	}
+	return index, nil
+}
@@ -0,0 +1,150 @@
+package library_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+	"github.com/fmartingr/games-screenshot-manager/pkg/library"
+	"github.com/sirupsen/logrus"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestIndexReserve
+// Tests that screenshots already in the library or being copied are detected
+// as duplicates regardless of their names
+func TestIndexReserve(t *testing.T) {
+	root := t.TempDir()
+	sources := t.TempDir()
+	hasher, _ := hashing.NewHasher(hashing.XXHash)
+
+	writeFile(t, filepath.Join(root, "PC", "Game", "steam.png"), "screenshot")
+	writeFile(t, filepath.Join(sources, "xbox.png"), "screenshot")
+	writeFile(t, filepath.Join(sources, "other.png"), "other shot")
+	writeFile(t, filepath.Join(sources, "copy.png"), "other shot")
+
+	index, err := library.OpenIndex(logrus.New(), root, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	duplicate, err := index.Reserve(filepath.Join(sources, "xbox.png"), filepath.Join(root, "PC", "Game", "xbox.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duplicate != filepath.Join(root, "PC", "Game", "steam.png") {
+		t.Errorf("Expected duplicate of steam.png, got %q", duplicate)
+	}
+
+	// Same size, different contents
+	other := filepath.Join(root, "PC", "Game", "other.png")
+	if duplicate, err := index.Reserve(filepath.Join(sources, "other.png"), other); err != nil || duplicate != "" {
+		t.Errorf("Expected no duplicate, got %q (%v)", duplicate, err)
+	}
+
+	// Duplicate of the screenshot still being copied
+	if duplicate, err := index.Reserve(filepath.Join(sources, "copy.png"), filepath.Join(root, "PC", "Game", "copy.png")); err != nil || duplicate != other {
+		t.Errorf("Expected duplicate of other.png, got %q (%v)", duplicate, err)
+	}
+}
+
+// TestIndexReserveConflict
+// Tests that destinations already in the library or reserved can't be
+// reserved again
+func TestIndexReserveConflict(t *testing.T) {
+	root := t.TempDir()
+	sources := t.TempDir()
+	hasher, _ := hashing.NewHasher(hashing.XXHash)
+
+	writeFile(t, filepath.Join(root, "PC", "Game", "capture.png"), "screenshot")
+	writeFile(t, filepath.Join(sources, "first.png"), "first")
+	writeFile(t, filepath.Join(sources, "second.png"), "second")
+
+	index, err := library.OpenIndex(logrus.New(), root, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := index.Reserve(filepath.Join(sources, "first.png"), filepath.Join(root, "PC", "Game", "capture.png")); !errors.Is(err, library.ErrReserved) {
+		t.Errorf("Expected ErrReserved for a destination in the library, got %v", err)
+	}
+
+	destination := filepath.Join(root, "PC", "Game", "new.png")
+	if _, err := index.Reserve(filepath.Join(sources, "first.png"), destination); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := index.Reserve(filepath.Join(sources, "second.png"), destination); !errors.Is(err, library.ErrReserved) {
+		t.Errorf("Expected ErrReserved for a reserved destination, got %v", err)
+	}
+}
+
+// TestIndexFind
+// Tests that files with the same contents are found without reserving them
+func TestIndexFind(t *testing.T) {
+	root := t.TempDir()
+	sources := t.TempDir()
+	hasher, _ := hashing.NewHasher(hashing.XXHash)
+
+	writeFile(t, filepath.Join(root, "PC", "Game", "steam.png"), "screenshot")
+	writeFile(t, filepath.Join(sources, "xbox.png"), "screenshot")
+	writeFile(t, filepath.Join(sources, "other.png"), "other shot")
+
+	index, err := library.OpenIndex(logrus.New(), root, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if duplicate, err := index.Find(filepath.Join(sources, "xbox.png")); err != nil || duplicate != filepath.Join(root, "PC", "Game", "steam.png") {
+		t.Errorf("Expected steam.png, got %q (%v)", duplicate, err)
+	}
+	if duplicate, err := index.Find(filepath.Join(sources, "other.png")); err != nil || duplicate != "" {
+		t.Errorf("Expected no duplicate, got %q (%v)", duplicate, err)
+	}
+}
+
+// TestIndexSave
+// Tests that hashes are kept across runs for files that didn't change
+func TestIndexSave(t *testing.T) {
+	root := t.TempDir()
+	hasher, _ := hashing.NewHasher(hashing.SHA256)
+	path := filepath.Join(root, "PC", "Game", "screenshot.png")
+	writeFile(t, path, "screenshot")
+
+	index, err := library.OpenIndex(logrus.New(), root, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := index.Hash(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the file keeping its size and time, so only the saved hash
+	// matches the old contents
+	info, _ := os.Stat(path)
+	writeFile(t, path, "SCREENSHOT")
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err = library.OpenIndex(logrus.New(), root, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := index.Hash(path); err != nil || saved != hash {
+		t.Errorf("Expected saved hash %s, got %s (%v)", hash, saved, err)
+	}
+}
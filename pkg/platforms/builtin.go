@@ -0,0 +1,90 @@
+package platforms
+
+// builtinPlatforms maps canonical platform names to the names used for them by
+// libretro databases, providers and emulators.
+var builtinPlatforms = map[string][]string{
+	// PC
+	"PC": {"windows", "win", "linux", "macos"},
+
+	// Nintendo
+	"Nintendo Entertainment System":       {"Nintendo - Nintendo Entertainment System", "nes", "famicom"},
+	"Family Computer Disk System":         {"Nintendo - Family Computer Disk System", "fds"},
+	"Super Nintendo Entertainment System": {"Nintendo - Super Nintendo Entertainment System", "snes", "sfc", "super famicom"},
+	"Nintendo 64":                         {"Nintendo - Nintendo 64", "n64"},
+	"Nintendo 64DD":                       {"Nintendo - Nintendo 64DD", "n64dd"},
+	"GameCube":                            {"Nintendo - GameCube", "gc", "ngc"},
+	"Wii":                                 {"Nintendo - Wii"},
+	"Wii U":                               {"Nintendo - Wii U", "wiiu"},
+	"Nintendo Switch":                     {"Nintendo - Switch", "switch", "nx", "nintendo-switch"},
+	"Game Boy":                            {"Nintendo - Game Boy", "gb"},
+	"Game Boy Color":                      {"Nintendo - Game Boy Color", "gbc"},
+	"Game Boy Advance":                    {"Nintendo - Game Boy Advance", "gba"},
+	"Nintendo DS":                         {"Nintendo - Nintendo DS", "nds", "ds"},
+	"Nintendo DSi":                        {"Nintendo - Nintendo DSi", "dsi"},
+	"Nintendo 3DS":                        {"Nintendo - Nintendo 3DS", "3ds", "n3ds"},
+	"Virtual Boy":                         {"Nintendo - Virtual Boy", "vb", "virtualboy"},
+	"Pokemon Mini":                        {"Nintendo - Pokemon Mini", "pokemini"},
+
+	// Sony
+	"PlayStation":          {"Sony - PlayStation", "psx", "ps1", "playstation-1"},
+	"PlayStation 2":        {"Sony - PlayStation 2", "ps2", "playstation-2"},
+	"PlayStation 3":        {"Sony - PlayStation 3", "ps3", "playstation-3"},
+	"PlayStation 4":        {"Sony - PlayStation 4", "ps4", "playstation-4"},
+	"PlayStation 5":        {"Sony - PlayStation 5", "ps5", "playstation-5"},
+	"PlayStation Portable": {"Sony - PlayStation Portable", "psp"},
+	"PlayStation Vita":     {"Sony - PlayStation Vita", "psvita", "vita"},
+
+	// Sega
+	"SG-1000":       {"Sega - SG-1000", "sg1000"},
+	"Master System": {"Sega - Master System - Mark III", "sms", "mastersystem"},
+	"Mega Drive":    {"Sega - Mega Drive - Genesis", "genesis", "megadrive", "md"},
+	"Mega-CD":       {"Sega - Mega-CD - Sega CD", "segacd", "megacd"},
+	"32X":           {"Sega - 32X", "sega32x"},
+	"Saturn":        {"Sega - Saturn"},
+	"Dreamcast":     {"Sega - Dreamcast", "dc"},
+	"Game Gear":     {"Sega - Game Gear", "gg", "gamegear"},
+
+	// Microsoft
+	"MSX":      {"Microsoft - MSX"},
+	"MSX2":     {"Microsoft - MSX2"},
+	"Xbox":     {"Microsoft - Xbox"},
+	"Xbox 360": {"Microsoft - Xbox 360", "xbox360"},
+
+	// Atari
+	"Atari 2600":   {"Atari - 2600", "atari2600"},
+	"Atari 5200":   {"Atari - 5200", "atari5200"},
+	"Atari 7800":   {"Atari - 7800", "atari7800"},
+	"Atari Jaguar": {"Atari - Jaguar", "jaguar"},
+	"Atari Lynx":   {"Atari - Lynx", "lynx"},
+	"Atari ST":     {"Atari - ST", "atarist"},
+
+	// NEC
+	"PC Engine":    {"NEC - PC Engine - TurboGrafx 16", "pce", "pcengine", "tg16"},
+	"PC Engine CD": {"NEC - PC Engine CD - TurboGrafx-CD", "pcecd", "tgcd"},
+	"PC-FX":        {"NEC - PC-FX", "pcfx"},
+
+	// SNK
+	"Neo Geo":              {"SNK - Neo Geo", "neogeo"},
+	"Neo Geo CD":           {"SNK - Neo Geo CD", "neogeocd"},
+	"Neo Geo Pocket":       {"SNK - Neo Geo Pocket", "ngp"},
+	"Neo Geo Pocket Color": {"SNK - Neo Geo Pocket Color", "ngpc"},
+
+	// Others
+	"WonderSwan":          {"Bandai - WonderSwan", "wswan"},
+	"WonderSwan Color":    {"Bandai - WonderSwan Color", "wswanc", "wonderswancolor"},
+	"Arcade":              {"MAME", "FBNeo - Arcade Games", "fbneo"},
+	"3DO":                 {"The 3DO Company - 3DO"},
+	"ColecoVision":        {"Coleco - ColecoVision", "coleco"},
+	"Intellivision":       {"Mattel - Intellivision"},
+	"Vectrex":             {"GCE - Vectrex"},
+	"Commodore 64":        {"Commodore - 64", "c64"},
+	"Amiga":               {"Commodore - Amiga"},
+	"ZX Spectrum":         {"Sinclair - ZX Spectrum", "Sinclair - ZX Spectrum +3", "zxspectrum"},
+	"Amstrad CPC":         {"Amstrad - CPC", "amstradcpc", "cpc"},
+	"Magnavox Odyssey2":   {"Magnavox - Odyssey2", "odyssey2"},
+	"Fairchild Channel F": {"Fairchild - Channel F", "channelf"},
+	"Watara Supervision":  {"Watara - Supervision", "supervision"},
+	"Philips CD-i":        {"Philips - CD-i", "cdi"},
+	"Sharp X68000":        {"Sharp - X68000", "x68000"},
+	"NEC PC-98":           {"NEC - PC-98", "pc98"},
+}
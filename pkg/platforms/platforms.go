@@ -0,0 +1,117 @@
+package platforms
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+// Normalizer maps the platform names used by providers, libretro databases
+// and emulators to a canonical platform name, so the same platform always
+// ends up in the same output folder.
+type Normalizer struct {
+	// names maps the normalized keys of the aliases to canonical names
+	names map[string]string
+	// aliases maps the aliases, as provided, to canonical names
+	aliases map[string]string
+}
+
+func normalizeKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Add maps an alias to a canonical platform name, overriding any existing
+// mapping for that alias. If the alias is a canonical name itself, all of its
+// aliases are renamed too.
+func (n *Normalizer) Add(alias, canonical string) {
+	key := normalizeKey(alias)
+	if previous, exists := n.names[key]; exists && normalizeKey(previous) == key {
+		for k, v := range n.names {
+			if v == previous {
+				n.names[k] = canonical
+			}
+		}
+		for k, v := range n.aliases {
+			if v == previous {
+				n.aliases[k] = canonical
+			}
+		}
+	}
+
+	n.names[key] = canonical
+	n.aliases[alias] = canonical
+	if _, exists := n.names[normalizeKey(canonical)]; !exists {
+		n.names[normalizeKey(canonical)] = canonical
+	}
+}
+
+// LoadFile reads user overrides from a JSON file mapping aliases to canonical
+// platform names, like: {"Sega - Mega Drive - Genesis": "Genesis"}
+func (n *Normalizer) LoadFile(path string) error {
+	contents, err := os.ReadFile(helpers.ExpandUser(path))
+	if err != nil {
+		return fmt.Errorf("error reading platforms file: %s", err)
+	}
+
+	var overrides map[string]string
+	if err := json.Unmarshal(contents, &overrides); err != nil {
+		return fmt.Errorf("error parsing platforms file: %s", err)
+	}
+
+	for alias, canonical := range overrides {
+		n.Add(alias, canonical)
+	}
+
+	return nil
+}
+
+// Normalize returns the canonical name for a platform, or the provided name
+// if the platform is unknown.
+func (n *Normalizer) Normalize(platform string) string {
+	if canonical, exists := n.names[normalizeKey(platform)]; exists {
+		return canonical
+	}
+	return strings.TrimSpace(platform)
+}
+
+// Aliases returns the known names for a platform, sorted.
+func (n *Normalizer) Aliases(platform string) []string {
+	var result []string
+	canonical := n.Normalize(platform)
+	for alias, name := range n.aliases {
+		if name == canonical {
+			result = append(result, alias)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// NormalizeGames sets the canonical platform name on all the provided games.
+func (n *Normalizer) NormalizeGames(games []*models.Game) {
+	for _, game := range games {
+		game.Platform = n.Normalize(game.Platform)
+	}
+}
+
+// NewNormalizer returns a normalizer loaded with the built-in platform names.
+func NewNormalizer() *Normalizer {
+	n := &Normalizer{
+		names:   make(map[string]string),
+		aliases: make(map[string]string),
+	}
+
+	for canonical, aliases := range builtinPlatforms {
+		n.Add(canonical, canonical)
+		for _, alias := range aliases {
+			n.Add(alias, canonical)
+		}
+	}
+
+	return n
+}
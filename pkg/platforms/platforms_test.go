@@ -0,0 +1,57 @@
+package platforms_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/platforms"
+)
+
+// TestNormalizeBuiltin
+// Tests that names from libretro, providers and emulators end up on the same
+// canonical name and that unknown names are left as they are
+func TestNormalizeBuiltin(t *testing.T) {
+	normalizer := platforms.NewNormalizer()
+
+	for name, expected := range map[string]string{
+		"Sony - PlayStation":          "PlayStation",
+		"psx":                         "PlayStation",
+		"playstation-4":               "PlayStation 4",
+		"Sega - Mega Drive - Genesis": "Mega Drive",
+		"Nintendo - Game Boy Advance": "Game Boy Advance",
+		" GBA ":                       "Game Boy Advance",
+		"PC":                          "PC",
+		"My Custom Playlist":          "My Custom Playlist",
+	} {
+		if result := normalizer.Normalize(name); result != expected {
+			t.Errorf("Normalize(%q) = %q (should be %q)", name, result, expected)
+		}
+	}
+}
+
+// TestNormalizeOverrides
+// Tests that user overrides replace built-in names, including renaming a
+// canonical platform for all of its aliases
+func TestNormalizeOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "platforms.json")
+	if err := os.WriteFile(path, []byte(`{"Mega Drive": "Genesis", "psx": "PSX"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	normalizer := platforms.NewNormalizer()
+	if err := normalizer.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"Sega - Mega Drive - Genesis": "Genesis",
+		"md":                          "Genesis",
+		"psx":                         "PSX",
+		"Sony - PlayStation":          "PlayStation",
+	} {
+		if result := normalizer.Normalize(name); result != expected {
+			t.Errorf("Normalize(%q) = %q (should be %q)", name, result, expected)
+		}
+	}
+}
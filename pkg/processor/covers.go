@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/artwork"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+// coverExists checks if a cover with the provided name exists in the game
+// folder, regardless of its extension.
+func coverExists(destinationPath, name string) bool {
+	matches, _ := filepath.Glob(filepath.Join(destinationPath, name+".*"))
+	return len(matches) > 0
+}
+
+// coverFresh checks if a cover with the provided name was stored in the game
+// folder before it expired, so it doesn't need to be revalidated.
+func coverFresh(destinationPath, name string) bool {
+	matches, _ := filepath.Glob(filepath.Join(destinationPath, name+".*"))
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && time.Since(info.ModTime()) < artwork.Expiration {
+			return true
+		}
+	}
+	return false
+}
+
+// removeCover removes the covers with the provided name in the game folder
+// other than keep, regardless of their extension.
+func (p *Processor) removeCover(destinationPath, name, keep string) {
+	matches, _ := filepath.Glob(filepath.Join(destinationPath, name+".*"))
+	for _, match := range matches {
+		if match == keep {
+			continue
+		}
+		if err := os.Remove(match); err != nil {
+			p.emit(Event{Type: EventError, Err: fmt.Errorf("error removing cover %s: %s", match, err)})
+		}
+	}
+}
+
+// downloadCovers stores the covers for a game in its folder. By default only
+// the first cover that can be downloaded is stored as `cover.<extension>`,
+// falling back through the covers resolved for the game in order. If cover
+// variants are selected, each of them is stored as `cover-<kind>.<extension>`.
+// Existing covers are revalidated once expired, and kept if that fails, unless
+// covers are refreshed.
+func (p *Processor) downloadCovers(ctx context.Context, game *models.Game, destinationPath string) {
+	if len(p.options.CoverVariants) == 0 && !p.options.RefreshCovers && coverFresh(destinationPath, "cover") {
+		return
+	}
+
+	covers, err := p.artwork.Resolve(ctx, game)
+	if err != nil {
+		if errors.Is(err, models.ErrArtworkNotFound) {
+			p.logger.Debugf("No covers found for game %s from %s", game.Name, game.Provider)
+		} else {
+			p.emit(Event{Type: EventError, Game: game, Err: fmt.Errorf("error resolving covers for game %s from %s: %s", game.Name, game.Provider, err)})
+		}
+		return
+	}
+
+	if len(p.options.CoverVariants) == 0 {
+		for _, cover := range covers {
+			if err := p.downloadCover(ctx, game, cover, destinationPath, "cover"); err != nil {
+				p.logger.Debugf("Cover %s not available for game %s from %s: %s", cover.Kind, game.Name, game.Provider, err)
+				continue
+			}
+			return
+		}
+
+		if coverExists(destinationPath, "cover") {
+			p.logger.Debugf("Can't revalidate cover for game %s from %s, keeping the existing one", game.Name, game.Provider)
+			return
+		}
+		p.emit(Event{Type: EventError, Game: game, Err: fmt.Errorf("error downloading cover for game %s from %s: no cover available", game.Name, game.Provider)})
+		return
+	}
+
+	for _, cover := range covers {
+		name := "cover-" + cover.Kind
+		if !helpers.SliceContainsString(p.options.CoverVariants, cover.Kind, nil) {
+			continue
+		}
+		if !p.options.RefreshCovers && coverFresh(destinationPath, name) {
+			continue
+		}
+
+		if err := p.downloadCover(ctx, game, cover, destinationPath, name); err != nil {
+			if coverExists(destinationPath, name) {
+				p.logger.Debugf("Can't revalidate %s cover for game %s from %s, keeping the existing one: %s", cover.Kind, game.Name, game.Provider, err)
+				continue
+			}
+			p.emit(Event{Type: EventError, Game: game, Err: fmt.Errorf("error downloading %s cover for game %s from %s: %s", cover.Kind, game.Name, game.Provider, err)})
+		}
+	}
+}
+
+// downloadCover tries the cover URLs in order, storing the first one that can
+// be retrieved in the game folder, using an extension matching its contents.
+func (p *Processor) downloadCover(ctx context.Context, game *models.Game, cover models.Cover, destinationPath, name string) (err error) {
+	for _, coverURL := range cover.URLs {
+		var contents []byte
+		var contentType, extension string
+
+		contents, contentType, err = p.fetcher.Fetch(ctx, coverURL)
+		if err != nil {
+			continue
+		}
+
+		extension, err = artwork.Extension(contentType)
+		if err != nil {
+			continue
+		}
+
+		// Covers with other extensions are only removed once the new one is
+		// stored, so a failed download keeps the existing cover
+		path := filepath.Join(destinationPath, name+extension)
+		if err := helpers.WriteFileAtomic(path, contents, p.fileMode()); err != nil {
+			return err
+		}
+		if p.options.Owner != nil {
+			if err := os.Chown(path, p.options.Owner.UID, p.options.Owner.GID); err != nil {
+				return err
+			}
+		}
+		p.removeCover(destinationPath, name, path)
+		p.emit(Event{Type: EventCoverDownloaded, Game: game, Cover: path})
+		return nil
+	}
+
+	if err == nil {
+		err = models.ErrArtworkNotFound
+	}
+
+	return err
+}
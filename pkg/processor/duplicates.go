@@ -0,0 +1,186 @@
+package processor
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+)
+
+// Policies for screenshots of a game that look the same and were taken at
+// about the same time, like a JPG and a PNG of the same capture.
+const (
+	NearDuplicatesKeepAll  = "keep-all"
+	NearDuplicatesKeepBest = "keep-best"
+	NearDuplicatesMove     = "move"
+)
+
+// DuplicatesFolder is the folder in the game folder near duplicates are
+// stored in with the move policy.
+const DuplicatesFolder = "_duplicates"
+
+// DefaultNearDuplicatesWindow is the default time between screenshots to
+// consider them near duplicates.
+const DefaultNearDuplicatesWindow = 2 * time.Second
+
+// lossyFormats are the image formats ranked below the lossless ones when
+// choosing the best of the near duplicates.
+var lossyFormats = map[string]bool{
+	"jpeg": true,
+}
+
+// nearDuplicateCandidate is a screenshot that may look the same as others,
+// either one of the game or a file already in its folder.
+type nearDuplicateCandidate struct {
+	index    int
+	path     string
+	existing bool
+	modTime  time.Time
+	size     int64
+
+	hashed     bool
+	hash       uint64
+	format     string
+	resolution image.Point
+}
+
+// betterThan checks if the screenshot has better quality than the other one:
+// lossless over lossy formats, then higher resolution, then bigger files.
+func (c *nearDuplicateCandidate) betterThan(other *nearDuplicateCandidate) bool {
+	if lossyFormats[c.format] != lossyFormats[other.format] {
+		return !lossyFormats[c.format]
+	}
+	if pixels, otherPixels := c.resolution.X*c.resolution.Y, other.resolution.X*other.resolution.Y; pixels != otherPixels {
+		return pixels > otherPixels
+	}
+	return c.size > other.size
+}
+
+// existingCandidates returns the files in the game folder that may be near
+// duplicates of its screenshots, like the ones imported by another provider.
+// The destinations of the screenshots themselves are left out, as they are
+// checked when copying them.
+func existingCandidates(game *models.Game, destinationPath string) []*nearDuplicateCandidate {
+	entries, err := os.ReadDir(destinationPath)
+	if err != nil {
+		return nil
+	}
+
+	destinations := make(map[string]bool, len(game.Screenshots))
+	for _, screenshot := range game.Screenshots {
+		destinations[screenshot.GetDestinationName()] = true
+	}
+
+	var candidates []*nearDuplicateCandidate
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || destinations[name] || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "cover") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, &nearDuplicateCandidate{
+			index:    -1,
+			path:     filepath.Join(destinationPath, name),
+			existing: true,
+			modTime:  info.ModTime(),
+			size:     info.Size(),
+		})
+	}
+	return candidates
+}
+
+// nearDuplicates returns the index of the screenshots of the game that look
+// the same as a better one taken within the time window, mapped to the path
+// of the better one. Screenshots are also compared with the files already in
+// the game folder (relying on their modification time, kept unless disabled),
+// which are never removed: they are kept over new screenshots unless those
+// are better. Only screenshots taken close to others are decoded.
+func (p *Processor) nearDuplicates(game *models.Game, destinationPath string) map[int]string {
+	if p.options.NearDuplicates == "" || p.options.NearDuplicates == NearDuplicatesKeepAll || len(game.Screenshots) == 0 {
+		return nil
+	}
+
+	window := p.options.NearDuplicatesWindow
+	if window <= 0 {
+		window = DefaultNearDuplicatesWindow
+	}
+
+	candidates := existingCandidates(game, destinationPath)
+	for i, screenshot := range game.Screenshots {
+		info, err := os.Stat(screenshot.Path)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, &nearDuplicateCandidate{index: i, path: screenshot.Path, modTime: info.ModTime(), size: info.Size()})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].modTime.Before(candidates[j].modTime)
+	})
+
+	// Group the screenshots that look the same, each pointing to the best one
+	best := make(map[*nearDuplicateCandidate]*nearDuplicateCandidate)
+	find := func(c *nearDuplicateCandidate) *nearDuplicateCandidate {
+		for best[c] != nil && best[c] != c {
+			c = best[c]
+		}
+		return c
+	}
+
+	for i, candidate := range candidates {
+		for _, other := range candidates[i+1:] {
+			if other.modTime.Sub(candidate.modTime) > window {
+				break
+			}
+			if candidate.existing && other.existing {
+				continue
+			}
+			if !p.perceptualHash(candidate) || !p.perceptualHash(other) {
+				continue
+			}
+			if hashing.Distance(candidate.hash, other.hash) > hashing.DefaultDistance {
+				continue
+			}
+
+			a, b := find(candidate), find(other)
+			if a == b {
+				continue
+			}
+			if b.betterThan(a) || (!a.betterThan(b) && b.existing && !a.existing) {
+				a, b = b, a
+			}
+			best[a], best[b] = a, a
+		}
+	}
+
+	duplicates := make(map[int]string)
+	for candidate := range best {
+		if root := find(candidate); root != candidate && !candidate.existing {
+			duplicates[candidate.index] = root.path
+		}
+	}
+	return duplicates
+}
+
+// perceptualHash computes the perceptual hash of the candidate unless it's
+// already done, returning false if the screenshot isn't an image.
+func (p *Processor) perceptualHash(candidate *nearDuplicateCandidate) bool {
+	if !candidate.hashed {
+		candidate.hashed = true
+		hash, format, resolution, err := hashing.DHashFile(candidate.path)
+		if err != nil {
+			p.logger.Debugf("Can't get perceptual hash of %s: %s", candidate.path, err)
+			candidate.format = ""
+			return false
+		}
+		candidate.hash, candidate.format, candidate.resolution = hash, format, resolution
+	}
+	return candidate.format != ""
+}
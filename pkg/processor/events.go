@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+)
+
+// EventType identifies what happened to a game or screenshot.
+type EventType string
+
+const (
+	EventGameStarted       EventType = "game_started"
+	EventScreenshotCopied  EventType = "screenshot_copied"
+	EventScreenshotSkipped EventType = "screenshot_skipped"
+	EventConflict          EventType = "conflict"
+	EventCoverDownloaded   EventType = "cover_downloaded"
+	EventGameFinished      EventType = "game_finished"
+	EventError             EventType = "error"
+)
+
+// Event is something that happened while processing games. In dry runs the
+// screenshot events are for what would have been done.
+type Event struct {
+	Type EventType
+	Game *models.Game
+	// Screenshot is set for the screenshot and conflict events, and for the
+	// errors of a screenshot.
+	Screenshot *ScreenshotResult
+	// Result is set for the game finished events.
+	Result *GameResult
+	// Cover is the path of the downloaded cover.
+	Cover string
+	Err   error
+}
+
+// Observer receives the events of a processor. Events are sent from the
+// workers as they happen, so observers must be safe for concurrent use and
+// shouldn't block.
+type Observer interface {
+	OnEvent(event Event)
+}
+
+// ObserverFunc is a function used as an observer.
+type ObserverFunc func(event Event)
+
+func (f ObserverFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// Collector is an observer storing the results of the finished games.
+type Collector struct {
+	mu      sync.Mutex
+	results []GameResult
+}
+
+func (c *Collector) OnEvent(event Event) {
+	if event.Type != EventGameFinished {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, *event.Result)
+}
+
+// Results returns the results of the games finished so far.
+func (c *Collector) Results() []GameResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]GameResult{}, c.results...)
+}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Subscribe adds an observer for the events of the processor. Observers must
+// be subscribed before the processor is started.
+func (p *Processor) Subscribe(observer Observer) {
+	p.observers = append(p.observers, observer)
+}
+
+func (p *Processor) emit(event Event) {
+	for _, observer := range p.observers {
+		observer.OnEvent(event)
+	}
+}
+
+// screenshotEvent returns the event for the result of a screenshot, if any.
+func screenshotEvent(game *models.Game, result ScreenshotResult) (Event, bool) {
+	event := Event{Game: game, Screenshot: &result}
+	switch result.Action {
+	case ActionCopy:
+		event.Type = EventScreenshotCopied
+	case ActionSkip, ActionDuplicate:
+		event.Type = EventScreenshotSkipped
+	case ActionConflict:
+		event.Type = EventConflict
+	case ActionError:
+		event.Type = EventError
+		event.Err = errors.New(result.Reason)
+	default:
+		return event, false
+	}
+	return event, true
+}
@@ -0,0 +1,34 @@
+package processor
+
+import (
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+	"github.com/fmartingr/games-screenshot-manager/pkg/library"
+)
+
+// UseIndex makes the processor check the screenshots against all the files
+// in the library index, skipping the ones already stored under another name.
+// The index is updated with the copied screenshots, but not saved.
+func (p *Processor) UseIndex(index *library.Index) {
+	p.index = index
+}
+
+// sameContents checks if the source and the file in the library have the
+// same contents, using the hash stored in the index if any.
+func (p *Processor) sameContents(source, destination string) (bool, error) {
+	sourceHash, err := hashing.HashFile(p.hasher, source)
+	if err != nil {
+		return false, err
+	}
+
+	var destinationHash string
+	if p.index != nil {
+		destinationHash, err = p.index.Hash(destination)
+	} else {
+		destinationHash, err = hashing.HashFile(p.hasher, destination)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return sourceHash == destinationHash, nil
+}
@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/fmartingr/games-screenshot-manager/pkg/identity"
+	"github.com/gosimple/slug"
+)
+
+const (
+	GroupByPlatform = "platform"
+	GroupByGame     = "game"
+)
+
+// existingFolderName returns the name of the folder in parent for the same
+// game, so games named differently across providers or runs (case, trademark
+// symbols, editions) end up in the same folder. If there's none the provided
+// name is returned.
+func existingFolderName(parent, name string) string {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return name
+	}
+
+	key := identity.Key(name)
+	for _, entry := range entries {
+		if entry.IsDir() && identity.Key(entry.Name()) == key {
+			return entry.Name()
+		}
+	}
+
+	return name
+}
+
+// folderName returns the name of the folder in parent for the same game,
+// resolving it only once per run so games prepared at the same time by
+// different workers end up in the same folder, even before it's created.
+func (p *Processor) folderName(parent, name string) string {
+	key := filepath.Join(parent, identity.Key(name))
+
+	p.foldersMu.Lock()
+	defer p.foldersMu.Unlock()
+	if folderName, exists := p.folders[key]; exists {
+		return folderName
+	}
+
+	folderName := existingFolderName(parent, name)
+	p.folders[key] = folderName
+	return folderName
+}
+
+// gamePath returns the path for the game in the output folder, grouped by
+// platform (`<platform>/<game>`) or by game (`<game>/<platform>`).
+func (p *Processor) gamePath(game *models.Game, folderName string) string {
+	outputPath := helpers.ExpandUser(p.options.OutputPath)
+
+	if p.options.GroupBy == GroupByGame {
+		return filepath.Join(outputPath, p.folderName(outputPath, folderName), game.Platform)
+	}
+
+	platformPath := filepath.Join(outputPath, game.Platform)
+	return filepath.Join(platformPath, p.folderName(platformPath, folderName))
+}
+
+// existingGamePath returns the path for the game in the output folder, or the
+// slugified one if the game was stored there because its name wasn't a valid
+// folder name.
+func (p *Processor) existingGamePath(game *models.Game) string {
+	folderName := game.FolderName(p.options.MergeVariants)
+	destinationPath := p.gamePath(game, folderName)
+	if _, err := os.Stat(destinationPath); os.IsNotExist(err) {
+		if slugPath := p.gamePath(game, slug.Make(folderName)); slugPath != destinationPath {
+			if _, err := os.Stat(slugPath); err == nil {
+				return slugPath
+			}
+		}
+	}
+	return destinationPath
+}
@@ -1,144 +1,498 @@
 package processor
 
 import (
-	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/artwork"
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
 	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/fmartingr/games-screenshot-manager/pkg/library"
 	"github.com/gosimple/slug"
 	"github.com/sirupsen/logrus"
 )
 
+// ArtworkNamespace is the cache namespace downloaded covers are stored in.
+const ArtworkNamespace = "artwork"
+
+// Processor copies the screenshots of the games into the output path. Games
+// are prepared (folder creation and covers) by a set of workers that queue
+// their screenshots, which are then copied by another set of workers, so a
+// game with many screenshots doesn't keep the rest of the workers idle.
 type Processor struct {
-	logger  *logrus.Entry
-	options models.Options
+	logger   *logrus.Entry
+	options  models.Options
+	artwork  models.ArtworkResolver
+	fetcher  *artwork.Fetcher
+	limiter  *helpers.RateLimiter
+	hasher   models.Hasher
+	index    *library.Index
+	progress *Progress
+
+	games     chan *models.Game
+	files     chan fileJob
+	wg        *sync.WaitGroup
+	closeOnce sync.Once
+
+	// folders holds the game folder names resolved in the run
+	folders   map[string]string
+	foldersMu sync.Mutex
+
+	// destinations holds the screenshots copied to each destination in the
+	// run, as different screenshots may have the same destination name
+	destinations   map[string]*destination
+	destinationsMu sync.Mutex
+
+	observers []Observer
+}
+
+// gameJob tracks the screenshots of a game being copied, so its result is
+// stored once all of them are done.
+type gameJob struct {
+	game      *models.Game
+	result    GameResult
+	remaining int
+	mu        sync.Mutex
+}
+
+// destination serializes the screenshots copied to the same path, so the later
+// ones are compared with the first one instead of replacing it.
+type destination struct {
+	mu     sync.Mutex
+	source string
+}
 
-	games chan *models.Game
-	wg    *sync.WaitGroup
+// fileJob is a screenshot queued to be copied.
+type fileJob struct {
+	job         *gameJob
+	index       int
+	destination string
 }
 
 func (p *Processor) Start(ctx context.Context) {
+	preparers := &sync.WaitGroup{}
 	for i := 0; i < p.options.WorkersNum; i++ {
-		go p.process(ctx)
+		preparers.Add(1)
+		go func() {
+			defer preparers.Done()
+			p.prepare(ctx)
+		}()
+		go p.copy(ctx)
 	}
+
+	// No more screenshots are queued once all games are prepared
+	go func() {
+		preparers.Wait()
+		close(p.files)
+	}()
 }
 
-func (p *Processor) Process(game *models.Game) {
+// Process queues the game to be processed. Once ctx is cancelled games are no
+// longer queued, and their screenshots are reported as cancelled.
+func (p *Processor) Process(ctx context.Context, game *models.Game) error {
+	if err := ctx.Err(); err != nil {
+		p.cancelGame(game)
+		return err
+	}
+
 	p.wg.Add(1)
-	p.games <- game
+	select {
+	case <-ctx.Done():
+		p.wg.Done()
+		p.cancelGame(game)
+		return ctx.Err()
+	case p.games <- game:
+		return nil
+	}
 }
 
-func (p *Processor) process(ctx context.Context) {
-	p.logger.Debug("Worker started")
-	for {
-		select {
-		case <-ctx.Done():
-			return
+// prepare handles the queued games until the processor is closed, queuing
+// their screenshots. Games dequeued after ctx is cancelled are only reported
+// as cancelled.
+func (p *Processor) prepare(ctx context.Context) {
+	p.logger.Debug("Game worker started")
+	for game := range p.games {
+		if err := p.processGame(ctx, game); err != nil {
+			p.emit(Event{Type: EventError, Game: game, Err: fmt.Errorf("error processing game %s from %s: %s", game.Name, game.Provider, err)})
+		}
+	}
+}
 
-		case game := <-p.games:
-			if err := p.processGame(game); err != nil {
-				p.logger.Errorf("Error processing game %s from %s: %s", game.Name, game.Provider, err)
-			}
+// copy handles the queued screenshots until all games are prepared.
+func (p *Processor) copy(ctx context.Context) {
+	p.logger.Debug("File worker started")
+	for file := range p.files {
+		screenshot := file.job.game.Screenshots[file.index]
+		if ctx.Err() != nil {
+			p.progress.finishFile(screenshot.Path, 0)
+			p.finishScreenshot(file.job, file.index, cancelledScreenshots([]models.Screenshot{screenshot}, file.job.result.Destination)[0])
+			continue
+		}
+
+		var copied int64
+		var result ScreenshotResult
+		p.progress.startFile(file.job.game)
+		unlock, previous := p.lockDestination(file.destination, screenshot.Path)
+		if previous != "" && p.options.DryRun {
+			result = p.plannedScreenshot(screenshot, file.destination, previous)
+		} else {
+			result = p.processScreenshot(ctx, file.job.game, screenshot, file.destination, func(n int64) {
+				copied += n
+				p.progress.copyBytes(n)
+			})
+		}
+		unlock()
+		p.progress.finishFile(screenshot.Path, copied)
+		p.finishScreenshot(file.job, file.index, result)
+	}
+}
+
+// lockDestination waits until no other screenshot is being copied to the
+// destination, returning the function to release it and the source of the
+// first screenshot with the same destination in the run, if any.
+func (p *Processor) lockDestination(path, source string) (func(), string) {
+	p.destinationsMu.Lock()
+	d, exists := p.destinations[path]
+	if !exists {
+		d = &destination{source: source}
+		p.destinations[path] = d
+	}
+	p.destinationsMu.Unlock()
+
+	d.mu.Lock()
+	if d.source == source {
+		return d.mu.Unlock, ""
+	}
+	return d.mu.Unlock, d.source
+}
+
+// plannedScreenshot returns the result of a screenshot with the same
+// destination as a previous one in a dry run, where the previous one isn't
+// actually copied.
+func (p *Processor) plannedScreenshot(screenshot models.Screenshot, destinationPath, previous string) ScreenshotResult {
+	result := ScreenshotResult{
+		Source:      screenshot.Path,
+		Destination: destinationPath,
+	}
+
+	sourceHash, err := hashing.HashFile(p.hasher, screenshot.Path)
+	if err == nil {
+		var previousHash string
+		if previousHash, err = hashing.HashFile(p.hasher, previous); err == nil && sourceHash == previousHash {
+			result.Action = ActionSkip
+			result.Reason = "already copied from " + previous
+			return result
 		}
 	}
+	if err != nil {
+		result.Action = ActionError
+		result.Reason = fmt.Sprintf("Can't compare with %s: %s", previous, err)
+		return result
+	}
+
+	result.Action = ActionConflict
+	result.Reason = "a different file is copied to the destination from " + previous
+	return result
+}
+
+// finishScreenshot stores the result of a screenshot, and the one of its game
+// once all its screenshots are done.
+func (p *Processor) finishScreenshot(job *gameJob, index int, result ScreenshotResult) {
+	if event, ok := screenshotEvent(job.game, result); ok {
+		p.emit(event)
+	}
+
+	job.mu.Lock()
+	job.result.Screenshots[index] = result
+	job.remaining--
+	done := job.remaining == 0
+	job.mu.Unlock()
+
+	if done {
+		p.finishGame(job.game, job.result)
+		p.wg.Done()
+	}
 }
 
+// Progress returns the progress of the run. Its totals are only set for the
+// games passed to its Expect method.
+func (p *Processor) Progress() *Progress {
+	return p.progress
+}
+
+// Wait stops accepting games and waits for the queued ones to be processed.
 func (p *Processor) Wait() {
+	p.closeOnce.Do(func() { close(p.games) })
 	p.wg.Wait()
 }
 
-// TODO: Reduce into smaller functions
-func (p *Processor) processGame(game *models.Game) (err error) {
-	defer p.wg.Done()
+// finishGame reports the result of a game.
+func (p *Processor) finishGame(game *models.Game, result GameResult) {
+	p.emit(Event{Type: EventGameFinished, Game: game, Result: &result})
+}
 
-	p.logger.WithFields(logrus.Fields{
-		"provider": game.Provider,
-		"name":     game.Name,
-	}).Debugf("Processing game")
+// cancelGame reports the screenshots of a game left unprocessed.
+func (p *Processor) cancelGame(game *models.Game) {
+	if len(game.Screenshots) == 0 {
+		return
+	}
+
+	p.progress.skipGame(game)
+	destinationPath := p.existingGamePath(game)
+	p.finishGame(game, GameResult{
+		Provider:    game.Provider,
+		Platform:    game.Platform,
+		Name:        game.Name,
+		ID:          game.ID,
+		Notes:       game.Notes,
+		Destination: destinationPath,
+		Screenshots: cancelledScreenshots(game.Screenshots, destinationPath),
+	})
+}
+
+// processGame creates the folder of the game and downloads its covers, then
+// queues its screenshots to be copied. The game is done once they are, unless
+// an error is returned.
+func (p *Processor) processGame(ctx context.Context, game *models.Game) (err error) {
+	queued := false
+	defer func() {
+		if !queued {
+			p.wg.Done()
+		}
+	}()
 
 	// Do not continue if there's no screenshots
 	if len(game.Screenshots) == 0 {
 		return
 	}
 
-	destinationPath := filepath.Join(helpers.ExpandUser(p.options.OutputPath), game.Platform)
-	if len(game.Name) > 0 {
-		destinationPath = filepath.Join(destinationPath, game.Name)
-	} else {
-		p.logger.Warnf("found game with ID: %s from %s without a name", game.ID, game.Provider)
-		destinationPath = filepath.Join(destinationPath, game.ID)
+	if ctx.Err() != nil {
+		p.cancelGame(game)
+		return nil
+	}
+
+	p.emit(Event{Type: EventGameStarted, Game: game})
+
+	if len(game.Name) == 0 {
+		p.logger.Debugf("found game with ID: %s from %s without a name", game.ID, game.Provider)
+	}
+	folderName := game.FolderName(p.options.MergeVariants)
+	destinationPath := p.gamePath(game, folderName)
+
+	job := &gameJob{
+		game: game,
+		result: GameResult{
+			Provider: game.Provider,
+			Platform: game.Platform,
+			Name:     game.Name,
+			ID:       game.ID,
+			Notes:    game.Notes,
+		},
 	}
 
 	// Check if folder exists (create otherwise)
 	if _, err := os.Stat(destinationPath); os.IsNotExist(err) && !p.options.DryRun {
-		mkdirErr := os.MkdirAll(destinationPath, 0711)
+		mkdirErr := helpers.MkdirAll(destinationPath, p.dirMode(), p.options.Owner)
 		if mkdirErr != nil {
-			p.logger.Errorf("Couldn't create directory with name %s, falling back to %s", game.Name, slug.Make(game.Name))
-			destinationPath = filepath.Join(helpers.ExpandUser(p.options.OutputPath), game.Platform, slug.Make(game.Name))
-			os.MkdirAll(destinationPath, 0711)
+			p.logger.Errorf("Couldn't create directory with name %s, falling back to %s", folderName, slug.Make(folderName))
+			destinationPath = p.gamePath(game, slug.Make(folderName))
+			if err := helpers.MkdirAll(destinationPath, p.dirMode(), p.options.Owner); err != nil {
+				job.result.Destination = destinationPath
+				job.result.Error = fmt.Sprintf("couldn't create directory %s: %s", destinationPath, err)
+				p.progress.skipGame(game)
+				p.finishGame(game, job.result)
+				return errors.New(job.result.Error)
+			}
 		}
 	}
+	job.result.Destination = destinationPath
 
-	if p.options.DownloadCovers && !p.options.DryRun && game.CoverURL != "" {
-		destinationCoverPath := filepath.Join(destinationPath, ".cover")
-		coverPath, err := helpers.DownloadURLIntoTempFile(game.CoverURL)
-		if err != nil {
-			p.logger.Errorf("Error donwloading cover for game %s from %s: %s", game.Name, game.Provider, err)
-		} else {
-			if _, err := os.Stat(destinationCoverPath); os.IsNotExist(err) {
-				helpers.CopyFile(coverPath, destinationCoverPath)
+	if p.options.DownloadCovers && !p.options.DryRun {
+		p.downloadCovers(ctx, game, destinationPath)
+	}
+
+	duplicates := p.nearDuplicates(game, destinationPath)
+	duplicatesPath := filepath.Join(destinationPath, DuplicatesFolder)
+	if len(duplicates) > 0 && p.options.NearDuplicates == NearDuplicatesMove && !p.options.DryRun {
+		if err := helpers.MkdirAll(duplicatesPath, p.dirMode(), p.options.Owner); err != nil {
+			// Better to keep them with the rest than to lose them
+			p.emit(Event{Type: EventError, Game: game, Err: fmt.Errorf("couldn't create directory %s, keeping near duplicates: %s", duplicatesPath, err)})
+			duplicates = nil
+		}
+	}
+
+	job.result.Screenshots = make([]ScreenshotResult, len(game.Screenshots))
+	job.remaining = len(game.Screenshots)
+	queued = true
+	for i, screenshot := range game.Screenshots {
+		destination := filepath.Join(destinationPath, screenshot.GetDestinationName())
+		if best, isDuplicate := duplicates[i]; isDuplicate {
+			if p.options.NearDuplicates == NearDuplicatesMove {
+				destination = filepath.Join(duplicatesPath, screenshot.GetDestinationName())
+			} else {
+				p.progress.finishFile(screenshot.Path, 0)
+				p.finishScreenshot(job, i, ScreenshotResult{
+					Source:      screenshot.Path,
+					Destination: destination,
+					Action:      ActionDuplicate,
+					Reason:      "looks the same as " + best,
+				})
+				continue
 			}
 		}
 
+		p.files <- fileJob{
+			job:         job,
+			index:       i,
+			destination: destination,
+		}
 	}
 
-	for _, screenshot := range game.Screenshots {
-		destinationPath := filepath.Join(destinationPath, screenshot.GetDestinationName())
+	return nil
+}
 
-		if _, err := os.Stat(destinationPath); !os.IsNotExist(err) {
-			sourceMd5, err := helpers.Md5File(screenshot.Path)
-			if err != nil {
-				p.logger.Errorf("Can't get hash of source file for game %s from %s: %s", game.Name, game.Provider, err)
-				return err
-			}
-			destinationMd5, err := helpers.Md5File(destinationPath)
-			if err != nil {
-				p.logger.Errorf("Can't get hash of destination file for game %s from %s: %s", game.Name, game.Provider, err)
-				return err
-			}
+// processScreenshot copies a screenshot to its destination unless it's
+// already there, calling onCopy with the bytes copied as it goes.
+func (p *Processor) processScreenshot(ctx context.Context, game *models.Game, screenshot models.Screenshot, destinationPath string, onCopy func(n int64)) ScreenshotResult {
+	result := ScreenshotResult{
+		Source:      screenshot.Path,
+		Destination: destinationPath,
+	}
+	fail := func(format string, args ...interface{}) ScreenshotResult {
+		result.Action = ActionError
+		result.Reason = fmt.Sprintf(format, args...)
+		return result
+	}
 
-			if !bytes.Equal(sourceMd5, destinationMd5) {
-				// Images are not equal, we should copy it anyway, but how?
-				p.logger.Warnf("Found different screenshot with equal timestamp for game %s from %s on %s", game.Name, game.Provider, screenshot.Path)
-			}
+	sourceInfo, err := os.Stat(screenshot.Path)
+	if err != nil {
+		return fail("Can't read source file %s: %s", screenshot.Path, err)
+	}
+	result.Bytes = sourceInfo.Size()
+
+	if _, err := os.Stat(destinationPath); !os.IsNotExist(err) {
+		same, err := p.sameContents(screenshot.Path, destinationPath)
+		if err != nil {
+			return fail("Can't compare with destination file: %s", err)
+		}
+
+		if !same {
+			// Images are not equal, we should copy it anyway, but how?
+			result.Action = ActionConflict
+			result.Reason = "a different file exists in the destination"
+			return result
+		}
+
+		result.Action = ActionSkip
+		result.Reason = "already exists"
+		return result
+	}
+
+	if p.index != nil {
+		duplicate, err := p.index.Reserve(screenshot.Path, destinationPath)
+		if errors.Is(err, library.ErrReserved) {
+			result.Action = ActionConflict
+			result.Reason = "the destination is already in the library index"
+			return result
+		}
+		if err != nil {
+			return fail("Can't look for duplicates of %s: %s", screenshot.Path, err)
+		}
+		if duplicate != "" {
+			result.Action = ActionDuplicate
+			result.Reason = "same contents as " + duplicate
+			return result
+		}
+	}
+
+	result.Action = ActionCopy
+	if p.options.DryRun {
+		return result
+	}
 
+	if result.Bytes, err = helpers.CopyFile(ctx, screenshot.Path, destinationPath, helpers.CopyOptions{
+		Limiter:       p.limiter,
+		Progress:      onCopy,
+		Mode:          p.fileMode(),
+		PreserveTimes: p.options.PreserveTimes,
+		Owner:         p.options.Owner,
+	}); errors.Is(err, context.Canceled) {
+		result.Action = ActionCancelled
+		result.Reason = "interrupted, partial copy removed"
+	} else if errors.Is(err, helpers.ErrCopyFileDestinationExists) {
+		result.Action = ActionConflict
+		result.Reason = "a file was created in the destination during the copy"
+	} else if err != nil {
+		result.Action = ActionError
+		result.Reason = fmt.Sprintf("error during copy operation: %s", err)
+	}
+
+	if p.index != nil {
+		if result.Action == ActionCopy {
+			p.index.Commit(destinationPath)
 		} else {
-			if p.options.DryRun {
-				p.logger.Infof("cp %s %s", filepath.Base(screenshot.Path), strings.Replace(destinationPath, helpers.ExpandUser(p.options.OutputPath), "", 1))
-			} else {
-				if _, err := helpers.CopyFile(screenshot.Path, destinationPath); err != nil {
-					p.logger.WithFields(logrus.Fields{
-						"src":  screenshot.Path,
-						"dest": destinationPath,
-					}).Errorf("Error during copy operation: %s", err)
-				}
-			}
+			p.index.Release(destinationPath)
 		}
 	}
 
-	return nil
+	return result
 }
 
-func NewProcessor(logger *logrus.Logger, options models.Options) *Processor {
-	return &Processor{
-		logger:  logger.WithField("from", "processor"),
-		games:   make(chan *models.Game, options.ProcessBufferSize),
-		options: options,
-		wg:      &sync.WaitGroup{},
+// fileMode returns the mode for the stored files.
+func (p *Processor) fileMode() os.FileMode {
+	if p.options.FileMode == 0 {
+		return helpers.DefaultFileMode
 	}
+	return p.options.FileMode
+}
+
+// dirMode returns the mode for the created folders.
+func (p *Processor) dirMode() os.FileMode {
+	if p.options.DirMode == 0 {
+		return helpers.DefaultDirMode
+	}
+	return p.options.DirMode
+}
+
+// NewProcessor returns a processor for the provided options. Covers are
+// resolved using the artwork resolver, if none is provided only the covers
+// set by the providers are used. Downloaded covers are stored in the cache.
+// Screenshots are hashed with the default algorithm unless one is set.
+func NewProcessor(logger *logrus.Logger, cache models.Cache, options models.Options, artworkResolver models.ArtworkResolver) (*Processor, error) {
+	if options.HashAlgorithm == "" {
+		options.HashAlgorithm = hashing.Default
+	}
+	hasher, err := hashing.NewHasher(options.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if artworkResolver == nil {
+		artworkResolver = artwork.NewProviderResolver()
+	}
+
+	if cache != nil {
+		cache = cache.Namespace(ArtworkNamespace)
+	}
+
+	return &Processor{
+		logger:   logger.WithField("from", "processor"),
+		artwork:  artworkResolver,
+		fetcher:  artwork.NewFetcher(logger, cache, options.RefreshCovers),
+		limiter:  helpers.NewRateLimiter(options.IOLimit),
+		progress: NewProgress(),
+		hasher:   hasher,
+		games:    make(chan *models.Game, options.ProcessBufferSize),
+		files:    make(chan fileJob, options.ProcessBufferSize),
+		options:  options,
+		wg:       &sync.WaitGroup{},
+		folders:  make(map[string]string),
+
+		destinations: make(map[string]*destination),
+	}, nil
 }
@@ -0,0 +1,411 @@
+package processor_test
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/hashing"
+	"github.com/fmartingr/games-screenshot-manager/pkg/library"
+	"github.com/fmartingr/games-screenshot-manager/pkg/processor"
+	"github.com/sirupsen/logrus"
+)
+
+func newProcessor(t *testing.T, options models.Options, artworkResolver models.ArtworkResolver) *processor.Processor {
+	p, err := processor.NewProcessor(logrus.New(), nil, options, artworkResolver)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return p
+}
+
+// TestNewProcessorHashAlgorithm
+// Tests that unknown hash algorithms are rejected instead of using the
+// default one
+func TestNewProcessorHashAlgorithm(t *testing.T) {
+	if _, err := processor.NewProcessor(logrus.New(), nil, models.Options{HashAlgorithm: "md4"}, nil); err == nil {
+		t.Error("expected an error for an unknown hash algorithm")
+	}
+}
+
+// TestProcessorEvents
+// Tests that observers receive the events of the screenshots and games
+// processed, and that the collector gathers the game results
+func TestProcessorEvents(t *testing.T) {
+	source := t.TempDir()
+	output := t.TempDir()
+
+	game := &models.Game{Name: "Game", Provider: "test", Platform: "PC"}
+	for _, name := range []string{"first.png", "second.png"} {
+		path := filepath.Join(source, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		game.Screenshots = append(game.Screenshots, models.Screenshot{Path: path, DestinationName: name})
+	}
+
+	// The second screenshot already exists in the destination
+	if err := os.MkdirAll(filepath.Join(output, "PC", "Game"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(output, "PC", "Game", "second.png"), []byte("second.png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := newProcessor(t, models.Options{
+		OutputPath: output,
+		GroupBy:    processor.GroupByPlatform,
+		WorkersNum: 2,
+	}, nil)
+
+	var mu sync.Mutex
+	events := make(map[processor.EventType]int)
+	p.Subscribe(processor.ObserverFunc(func(event processor.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events[event.Type]++
+	}))
+	results := processor.NewCollector()
+	p.Subscribe(results)
+
+	p.Start(context.Background())
+	if err := p.Process(context.Background(), game); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+
+	expected := map[processor.EventType]int{
+		processor.EventGameStarted:       1,
+		processor.EventScreenshotCopied:  1,
+		processor.EventScreenshotSkipped: 1,
+		processor.EventGameFinished:      1,
+	}
+	for eventType, count := range expected {
+		if events[eventType] != count {
+			t.Errorf("Got %d %s events (should be %d)", events[eventType], eventType, count)
+		}
+	}
+	if len(events) != len(expected) {
+		t.Errorf("Got unexpected events: %v", events)
+	}
+
+	if games := results.Results(); len(games) != 1 || games[0].Summary().Copied != 1 || games[0].Summary().Skipped != 1 {
+		t.Errorf("Unexpected results: %+v", games)
+	}
+}
+
+func writeTestImage(t *testing.T, path string, seed int, encode func(io.Writer, image.Image) error) {
+	img := image.NewRGBA(image.Rect(0, 0, 160, 90))
+	for y := 0; y < 90; y++ {
+		for x := 0; x < 160; x++ {
+			v := uint8((x*seed + y*(7-seed)) % 256)
+			img.Set(x, y, color.RGBA{v, 255 - v, uint8(x + y), 255})
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestProcessorNearDuplicates
+// Tests that only the best of the screenshots that look the same is copied
+// with the keep-best policy, and the rest moved with the move policy
+func TestProcessorNearDuplicates(t *testing.T) {
+	source := t.TempDir()
+	encodeJPEG := func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, nil) }
+	writeTestImage(t, filepath.Join(source, "capture.png"), 1, png.Encode)
+	writeTestImage(t, filepath.Join(source, "capture.jpg"), 1, encodeJPEG)
+	writeTestImage(t, filepath.Join(source, "other.jpg"), 5, encodeJPEG)
+
+	game := &models.Game{Name: "Game", Provider: "test", Platform: "PC"}
+	for _, name := range []string{"capture.jpg", "capture.png", "other.jpg"} {
+		game.Screenshots = append(game.Screenshots, models.Screenshot{Path: filepath.Join(source, name), DestinationName: name})
+	}
+
+	for policy, expected := range map[string][]string{
+		processor.NearDuplicatesKeepBest: {"capture.png", "other.jpg"},
+		processor.NearDuplicatesMove:     {"_duplicates/capture.jpg", "capture.png", "other.jpg"},
+	} {
+		output := t.TempDir()
+		p := newProcessor(t, models.Options{
+			OutputPath:     output,
+			GroupBy:        processor.GroupByPlatform,
+			WorkersNum:     1,
+			NearDuplicates: policy,
+		}, nil)
+		p.Start(context.Background())
+		if err := p.Process(context.Background(), game); err != nil {
+			t.Fatal(err)
+		}
+		p.Wait()
+
+		gamePath := filepath.Join(output, "PC", "Game")
+		var files []string
+		filepath.WalkDir(gamePath, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				relative, _ := filepath.Rel(gamePath, path)
+				files = append(files, filepath.ToSlash(relative))
+			}
+			return nil
+		})
+		if strings.Join(files, ",") != strings.Join(expected, ",") {
+			t.Errorf("Got files %v with policy %s (should be %v)", files, policy, expected)
+		}
+	}
+}
+
+// TestProcessorNearDuplicatesInFolder
+// Tests that screenshots are also compared with the files already in the game
+// folder, like the ones imported by another provider, which are kept
+func TestProcessorNearDuplicatesInFolder(t *testing.T) {
+	source := t.TempDir()
+	encodeJPEG := func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, nil) }
+	writeTestImage(t, filepath.Join(source, "steam.jpg"), 1, encodeJPEG)
+	writeTestImage(t, filepath.Join(source, "other.jpg"), 5, encodeJPEG)
+
+	game := &models.Game{Name: "Game", Provider: "test", Platform: "PC"}
+	for _, name := range []string{"steam.jpg", "other.jpg"} {
+		game.Screenshots = append(game.Screenshots, models.Screenshot{Path: filepath.Join(source, name), DestinationName: name})
+	}
+
+	output := t.TempDir()
+	gamePath := filepath.Join(output, "PC", "Game")
+	if err := os.MkdirAll(gamePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestImage(t, filepath.Join(gamePath, "gamebar.png"), 1, png.Encode)
+
+	p := newProcessor(t, models.Options{
+		OutputPath:     output,
+		GroupBy:        processor.GroupByPlatform,
+		WorkersNum:     1,
+		NearDuplicates: processor.NearDuplicatesKeepBest,
+	}, nil)
+	results := processor.NewCollector()
+	p.Subscribe(results)
+	p.Start(context.Background())
+	if err := p.Process(context.Background(), game); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+
+	entries, err := os.ReadDir(gamePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	if expected := []string{"gamebar.png", "other.jpg"}; strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("Got files %v (should be %v)", files, expected)
+	}
+
+	// Verified against the same files
+	verify, err := p.Verify(game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(verify) != 1 || verify[0].Status != processor.StatusOK {
+		t.Errorf("Unexpected verify results: %+v", verify)
+	}
+}
+
+// TestProcessorAliasFolders
+// Tests that games with names for the same game prepared at the same time end
+// up in the same folder
+func TestProcessorAliasFolders(t *testing.T) {
+	source := t.TempDir()
+	output := t.TempDir()
+
+	p := newProcessor(t, models.Options{
+		OutputPath: output,
+		GroupBy:    processor.GroupByPlatform,
+		WorkersNum: 8,
+	}, nil)
+	p.Start(context.Background())
+
+	const games = 20
+	for i := 0; i < games; i++ {
+		for j, name := range []string{"Game %d", "GAME %d™"} {
+			name = fmt.Sprintf(name, i)
+			path := filepath.Join(source, fmt.Sprintf("%d-%d.png", i, j))
+			if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+				t.Fatal(err)
+			}
+			game := &models.Game{Name: name, Provider: "test", Platform: "PC"}
+			game.Screenshots = []models.Screenshot{{Path: path, DestinationName: filepath.Base(path)}}
+			if err := p.Process(context.Background(), game); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	p.Wait()
+
+	entries, err := os.ReadDir(filepath.Join(output, "PC"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != games {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("Got %d game folders (should be %d): %s", len(entries), games, strings.Join(names, ", "))
+	}
+}
+
+// TestProcessorSameDestination
+// Tests that screenshots with the same destination name don't replace each
+// other: the first one is copied and the rest compared with it
+func TestProcessorSameDestination(t *testing.T) {
+	source := t.TempDir()
+
+	game := &models.Game{Name: "Game", Provider: "test", Platform: "PC"}
+	for i, contents := range []string{"first", "second", "first"} {
+		path := filepath.Join(source, fmt.Sprintf("%d.png", i))
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		game.Screenshots = append(game.Screenshots, models.Screenshot{Path: path, DestinationName: "capture.png"})
+	}
+
+	for _, dryRun := range []bool{false, true} {
+		output := t.TempDir()
+		p := newProcessor(t, models.Options{
+			OutputPath: output,
+			GroupBy:    processor.GroupByPlatform,
+			WorkersNum: 4,
+			DryRun:     dryRun,
+		}, nil)
+		results := processor.NewCollector()
+		p.Subscribe(results)
+
+		p.Start(context.Background())
+		if err := p.Process(context.Background(), game); err != nil {
+			t.Fatal(err)
+		}
+		p.Wait()
+
+		games := results.Results()
+		if len(games) != 1 {
+			t.Fatalf("Unexpected results: %+v", games)
+		}
+		summary := games[0].Summary()
+		if summary.Copied != 1 || summary.Conflicts+summary.Skipped != 2 || summary.Errors != 0 {
+			t.Errorf("Dry run %t: unexpected summary %+v", dryRun, summary)
+		}
+
+		if dryRun {
+			continue
+		}
+		var copied string
+		for _, result := range games[0].Screenshots {
+			if result.Action == processor.ActionCopy {
+				copied = result.Source
+			}
+		}
+		expected, _ := os.ReadFile(copied)
+		if contents, err := os.ReadFile(filepath.Join(output, "PC", "Game", "capture.png")); err != nil || string(contents) != string(expected) {
+			t.Errorf("Destination should hold the copied screenshot %s, got %q, %v", copied, contents, err)
+		}
+	}
+}
+
+// TestProcessorProgressCancelled
+// Tests that the screenshots of cancelled games are counted as done, so the
+// progress reaches its totals
+func TestProcessorProgressCancelled(t *testing.T) {
+	source := t.TempDir()
+	path := filepath.Join(source, "capture.png")
+	if err := os.WriteFile(path, []byte("capture"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	game := &models.Game{Name: "Game", Provider: "test", Platform: "PC"}
+	game.Screenshots = []models.Screenshot{{Path: path, DestinationName: "capture.png"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := newProcessor(t, models.Options{
+		OutputPath: t.TempDir(),
+		GroupBy:    processor.GroupByPlatform,
+		WorkersNum: 1,
+	}, nil)
+	p.Progress().Expect([]*models.Game{game})
+	p.Start(ctx)
+	p.Process(ctx, game)
+	p.Wait()
+
+	if status := p.Progress().Status(); status.Files != status.FilesTotal || status.Bytes != status.BytesTotal {
+		t.Errorf("Progress should be complete, got %+v", status)
+	}
+}
+
+// TestProcessorVerifyIndex
+// Tests that screenshots skipped as duplicates of files stored under another
+// name are verified as present
+func TestProcessorVerifyIndex(t *testing.T) {
+	source := t.TempDir()
+	output := t.TempDir()
+
+	game := &models.Game{Name: "Game", Provider: "test", Platform: "PC"}
+	for _, name := range []string{"first.png", "second.png"} {
+		path := filepath.Join(source, name)
+		if err := os.WriteFile(path, []byte("same capture"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		game.Screenshots = append(game.Screenshots, models.Screenshot{Path: path, DestinationName: name})
+	}
+
+	hasher, _ := hashing.NewHasher(hashing.Default)
+	options := models.Options{
+		OutputPath: output,
+		GroupBy:    processor.GroupByPlatform,
+		WorkersNum: 1,
+	}
+	index, err := library.OpenIndex(logrus.New(), output, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newProcessor(t, options, nil)
+	p.UseIndex(index)
+	p.Start(context.Background())
+	if err := p.Process(context.Background(), game); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+
+	if index, err = library.OpenIndex(logrus.New(), output, hasher); err != nil {
+		t.Fatal(err)
+	}
+	p = newProcessor(t, options, nil)
+	p.UseIndex(index)
+	results, err := p.Verify(game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Status != processor.StatusOK {
+			t.Errorf("%s should be present, got %s", result.Screenshot.Path, result.Status)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package processor
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+)
+
+// Progress tracks how much of a run is done, fed by the copy workers.
+type Progress struct {
+	mu    sync.Mutex
+	start time.Time
+	sizes map[string]int64
+
+	game       string
+	files      int
+	filesTotal int
+	bytes      int64
+	bytesTotal int64
+	inFlight   int64
+	copied     int64
+}
+
+// ProgressStatus is a snapshot of the progress of a run. Bytes count both the
+// copied and the skipped screenshots, while the throughput only counts the
+// copied ones.
+type ProgressStatus struct {
+	Game       string
+	Files      int
+	FilesTotal int
+	Bytes      int64
+	BytesTotal int64
+	Elapsed    time.Duration
+	// Throughput in bytes per second
+	Throughput float64
+	// ETA is zero until something has been copied
+	ETA time.Duration
+}
+
+// Expect adds the screenshots of the games to the totals.
+func (p *Progress) Expect(games []*models.Game) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, game := range games {
+		for _, screenshot := range game.Screenshots {
+			var size int64
+			if info, err := os.Stat(screenshot.Path); err == nil {
+				size = info.Size()
+			}
+			p.sizes[screenshot.Path] = size
+			p.filesTotal++
+			p.bytesTotal += size
+		}
+	}
+}
+
+// Status returns the current progress.
+func (p *Progress) Status() ProgressStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := ProgressStatus{
+		Game:       p.game,
+		Files:      p.files,
+		FilesTotal: p.filesTotal,
+		Bytes:      p.bytes + p.inFlight,
+		BytesTotal: p.bytesTotal,
+		Elapsed:    time.Since(p.start),
+	}
+
+	if seconds := status.Elapsed.Seconds(); seconds > 0 && p.copied > 0 {
+		status.Throughput = float64(p.copied) / seconds
+		if remaining := status.BytesTotal - status.Bytes; remaining > 0 {
+			status.ETA = time.Duration(float64(remaining) / status.Throughput * float64(time.Second))
+		}
+	}
+
+	return status
+}
+
+func (p *Progress) startFile(game *models.Game) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.game = game.Name
+	if p.game == "" {
+		p.game = game.ID
+	}
+	if game.Notes != "" {
+		p.game += " (" + game.Notes + ")"
+	}
+}
+
+func (p *Progress) copyBytes(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inFlight += n
+	p.copied += n
+}
+
+// finishFile marks a screenshot as done, whether it was copied or not, given
+// the bytes copied for it.
+func (p *Progress) finishFile(path string, copied int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inFlight -= copied
+	p.bytes += p.sizes[path]
+	p.files++
+}
+
+// skipGame marks all the screenshots of a game as done without copying them,
+// for games that fail or are cancelled before queuing them.
+func (p *Progress) skipGame(game *models.Game) {
+	for _, screenshot := range game.Screenshots {
+		p.finishFile(screenshot.Path, 0)
+	}
+}
+
+func NewProgress() *Progress {
+	return &Progress{
+		start: time.Now(),
+		sizes: make(map[string]int64),
+	}
+}
@@ -0,0 +1,143 @@
+package processor
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+)
+
+// Actions taken (or planned, in dry runs) for a screenshot.
+const (
+	ActionCopy      = "copy"
+	ActionSkip      = "skip"
+	ActionDuplicate = "duplicate"
+	ActionConflict  = "conflict"
+	ActionError     = "error"
+	ActionCancelled = "cancelled"
+)
+
+// ScreenshotResult is what was done with a screenshot.
+type ScreenshotResult struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Action      string `json:"action"`
+	Bytes       int64  `json:"bytes"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// cancelledScreenshots returns the results for screenshots that weren't
+// processed because the run was cancelled.
+func cancelledScreenshots(screenshots []models.Screenshot, destinationPath string) []ScreenshotResult {
+	results := make([]ScreenshotResult, 0, len(screenshots))
+	for _, screenshot := range screenshots {
+		results = append(results, ScreenshotResult{
+			Source:      screenshot.Path,
+			Destination: filepath.Join(destinationPath, screenshot.GetDestinationName()),
+			Action:      ActionCancelled,
+			Reason:      "interrupted before processing",
+		})
+	}
+	return results
+}
+
+// GameResult is what was done with the screenshots of a game.
+type GameResult struct {
+	Provider    string             `json:"provider"`
+	Platform    string             `json:"platform"`
+	Name        string             `json:"name"`
+	ID          string             `json:"id"`
+	Notes       string             `json:"notes,omitempty"`
+	Destination string             `json:"destination"`
+	Screenshots []ScreenshotResult `json:"screenshots"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// Summary holds the totals of a run.
+type Summary struct {
+	Games       int   `json:"games"`
+	Screenshots int   `json:"screenshots"`
+	Copied      int   `json:"copied"`
+	Skipped     int   `json:"skipped"`
+	Duplicates  int   `json:"duplicates"`
+	Conflicts   int   `json:"conflicts"`
+	Errors      int   `json:"errors"`
+	Cancelled   int   `json:"cancelled"`
+	Bytes       int64 `json:"bytes"`
+}
+
+func (s *Summary) add(other Summary) {
+	s.Games += other.Games
+	s.Screenshots += other.Screenshots
+	s.Copied += other.Copied
+	s.Skipped += other.Skipped
+	s.Duplicates += other.Duplicates
+	s.Conflicts += other.Conflicts
+	s.Errors += other.Errors
+	s.Cancelled += other.Cancelled
+	s.Bytes += other.Bytes
+}
+
+// Summary returns the totals for the game. Bytes only count copied
+// screenshots.
+func (r GameResult) Summary() Summary {
+	summary := Summary{Games: 1}
+	if r.Error != "" {
+		summary.Errors++
+	}
+	for _, screenshot := range r.Screenshots {
+		summary.Screenshots++
+		switch screenshot.Action {
+		case ActionCopy:
+			summary.Copied++
+			summary.Bytes += screenshot.Bytes
+		case ActionSkip:
+			summary.Skipped++
+		case ActionDuplicate:
+			summary.Duplicates++
+		case ActionConflict:
+			summary.Conflicts++
+		case ActionError:
+			summary.Errors++
+		case ActionCancelled:
+			summary.Cancelled++
+		}
+	}
+	return summary
+}
+
+// Report is the result of a run, or its plan in dry runs.
+type Report struct {
+	DryRun  bool         `json:"dry_run"`
+	Games   []GameResult `json:"games"`
+	Summary Summary      `json:"summary"`
+}
+
+// NewReport returns the report for the results of the games, sorted by
+// provider, platform, name and variant.
+func NewReport(dryRun bool, games []GameResult) Report {
+	report := Report{DryRun: dryRun, Games: games}
+	if report.Games == nil {
+		report.Games = []GameResult{}
+	}
+
+	sort.SliceStable(report.Games, func(i, j int) bool {
+		a, b := report.Games[i], report.Games[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Platform != b.Platform {
+			return a.Platform < b.Platform
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Notes < b.Notes
+	})
+
+	for _, game := range report.Games {
+		report.Summary.add(game.Summary())
+	}
+
+	return report
+}
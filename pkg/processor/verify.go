@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+)
+
+// Status of a screenshot in the output folder.
+const (
+	StatusOK        = "ok"
+	StatusMissing   = "missing"
+	StatusDifferent = "different"
+)
+
+// VerifyResult is the status of a screenshot copy in the output folder.
+type VerifyResult struct {
+	Game        *models.Game
+	Screenshot  models.Screenshot
+	Destination string
+	Status      string
+}
+
+// Verify checks that the screenshots of a game are in the output folder with
+// the same contents. Near duplicates are checked according to the policy:
+// skipped if only the best one is kept, or in the duplicates folder if moved.
+// If an index is used, screenshots stored under another name are found too.
+func (p *Processor) Verify(game *models.Game) ([]VerifyResult, error) {
+	if len(game.Screenshots) == 0 {
+		return nil, nil
+	}
+
+	gamePath := p.existingGamePath(game)
+	duplicates := p.nearDuplicates(game, gamePath)
+	result := make([]VerifyResult, 0, len(game.Screenshots))
+
+	for i, screenshot := range game.Screenshots {
+		verifyResult := VerifyResult{
+			Game:        game,
+			Screenshot:  screenshot,
+			Destination: filepath.Join(gamePath, screenshot.GetDestinationName()),
+			Status:      StatusOK,
+		}
+		if _, isDuplicate := duplicates[i]; isDuplicate {
+			if p.options.NearDuplicates != NearDuplicatesMove {
+				continue
+			}
+			verifyResult.Destination = filepath.Join(gamePath, DuplicatesFolder, screenshot.GetDestinationName())
+		}
+
+		if _, err := os.Stat(verifyResult.Destination); os.IsNotExist(err) {
+			// Screenshots skipped as duplicates are stored under another name
+			if p.index != nil {
+				duplicate, err := p.index.Find(screenshot.Path)
+				if err != nil {
+					return result, fmt.Errorf("can't look for duplicates of %s: %s", screenshot.Path, err)
+				}
+				if duplicate != "" {
+					verifyResult.Destination = duplicate
+					result = append(result, verifyResult)
+					continue
+				}
+			}
+
+			verifyResult.Status = StatusMissing
+			result = append(result, verifyResult)
+			continue
+		}
+
+		same, err := p.sameContents(screenshot.Path, verifyResult.Destination)
+		if err != nil {
+			return result, fmt.Errorf("can't compare %s with %s: %s", screenshot.Path, verifyResult.Destination, err)
+		}
+		if !same {
+			verifyResult.Status = StatusDifferent
+		}
+
+		result = append(result, verifyResult)
+	}
+
+	return result, nil
+}
@@ -4,12 +4,24 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
 	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
 )
 
+type launcher struct {
+	name          string
+	instancesPath string
+}
+
+type launcherInstance struct {
+	name            string
+	screenshotsPath string
+}
+
 func getScreenshotsFromPath(game *models.Game, path string) error {
 	path = helpers.ExpandUser(path)
 	if _, err := os.Stat(path); !os.IsNotExist(err) {
@@ -20,9 +32,89 @@ func getScreenshotsFromPath(game *models.Game, path string) error {
 
 		for _, file := range files {
 			if strings.Contains(file.Name(), ".png") {
-				game.Screenshots = append(game.Screenshots, models.Screenshot{Path: path + "/" + file.Name(), DestinationName: file.Name()})
+				game.Screenshots = append(game.Screenshots, models.Screenshot{Path: filepath.Join(path, file.Name()), DestinationName: file.Name()})
 			}
 		}
 	}
 	return nil
 }
+
+// variant is an edition of the game with its own screenshots directories.
+type variant struct {
+	name             string
+	screenshotsPaths []string
+}
+
+func getVariantsForOS() []variant {
+	switch runtime.GOOS {
+	case "linux":
+		return []variant{
+			{name: variantJava, screenshotsPaths: []string{"~/.minecraft/screenshots"}},
+			{name: variantFlatpak, screenshotsPaths: []string{
+				"~/.var/app/com.mojang.Minecraft/.minecraft/screenshots",
+				"~/.var/app/com.mojang.Minecraft/data/minecraft/screenshots",
+			}},
+		}
+	case "windows":
+		return []variant{
+			{name: variantJava, screenshotsPaths: []string{filepath.Join(os.Getenv("APPDATA"), ".minecraft/screenshots")}},
+			{name: variantBedrock, screenshotsPaths: []string{filepath.Join(os.Getenv("LOCALAPPDATA"), "Packages", "Microsoft.MinecraftUWP_8wekyb3d8bbwe", "LocalState", "games", "com.mojang", "Screenshots")}},
+		}
+	case "darwin":
+		return []variant{
+			{name: variantJava, screenshotsPaths: []string{"~/Library/Application Support/minecraft/screenshots"}},
+		}
+	}
+	return []variant{{name: variantJava}}
+}
+
+func getLaunchersForOS() []launcher {
+	switch runtime.GOOS {
+	case "linux":
+		return []launcher{
+			{name: "Prism Launcher", instancesPath: "~/.local/share/PrismLauncher/instances"},
+			{name: "Prism Launcher", instancesPath: "~/.var/app/org.prismlauncher.PrismLauncher/data/PrismLauncher/instances"},
+		}
+	case "windows":
+		return []launcher{
+			{name: "Prism Launcher", instancesPath: filepath.Join(os.Getenv("APPDATA"), "PrismLauncher", "instances")},
+		}
+	case "darwin":
+		return []launcher{
+			{name: "Prism Launcher", instancesPath: "~/Library/Application Support/PrismLauncher/instances"},
+		}
+	}
+	return nil
+}
+
+// getLauncherInstances returns the instances of a launcher that hold a game
+// directory, which depending on the launcher version may be named
+// `.minecraft` or `minecraft`.
+func getLauncherInstances(l launcher) ([]launcherInstance, error) {
+	var result []launcherInstance
+	path := helpers.ExpandUser(l.instancesPath)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return result, nil
+	}
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s instances from %s: %s", l.name, path, err)
+	}
+
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+
+		for _, gameDir := range []string{".minecraft", "minecraft"} {
+			screenshotsPath := filepath.Join(path, file.Name(), gameDir, "screenshots")
+			if _, err := os.Stat(screenshotsPath); err == nil {
+				result = append(result, launcherInstance{name: file.Name(), screenshotsPath: screenshotsPath})
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -1,53 +1,93 @@
 package minecraft
 
 import (
-	"os"
-	"path/filepath"
-	"runtime"
+	"context"
 
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
 	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+	"github.com/gosimple/slug"
 	"github.com/sirupsen/logrus"
 )
 
-const Name = "minecraft"
+const (
+	Name         = "minecraft"
+	gameName     = "Minecraft"
+	platformName = "PC"
+)
+
+// Variants of the game, stored as the game Notes so the processor can tell
+// them apart.
+const (
+	variantJava    = "Java"
+	variantFlatpak = "Flatpak"
+	variantBedrock = "Bedrock"
+)
 
 type MinecraftProvider struct {
 	logger *logrus.Entry
 }
 
-func (p *MinecraftProvider) FindGames(options models.ProviderOptions) ([]*models.Game, error) {
-	var result []*models.Game
-	// Standalone minecraft
-	minecraftStandalone := models.Game{Name: "Minecraft", Platform: "PC", Notes: "Standalone"}
+// newGame returns the game for a variant, with its own ID so it can be told
+// apart in aliases. The Java edition is the default one and has no notes, so
+// it's stored in the same folder as in older versions.
+func (p *MinecraftProvider) newGame(variant string) *models.Game {
+	game := models.NewGame(slug.Make(variant), gameName, platformName, Name)
+	if variant != variantJava {
+		game.Notes = variant
+	}
+	return &game
+}
 
-	if runtime.GOOS == "linux" {
-		if err := getScreenshotsFromPath(&minecraftStandalone, "~/.minecraft/screenshots"); err != nil {
+func (p *MinecraftProvider) addScreenshotsFromPaths(game *models.Game, paths ...string) {
+	for _, path := range paths {
+		if err := getScreenshotsFromPath(game, path); err != nil {
 			p.logger.Error(err)
 		}
+	}
+}
 
-		// Flatpak minecraft
-		minecraftFlatpak := models.Game{Name: "Minecraft", Platform: "PC", Notes: "Flatpak"}
-		for _, path := range [2]string{"~/.var/app/com.mojang.Minecraft/.minecraft/screenshots", "~/.var/app/com.mojang.Minecraft/data/minecraft/screenshots"} {
-			if err := getScreenshotsFromPath(&minecraftFlatpak, path); err != nil {
-				p.logger.Error(err)
-			}
-		}
-		result = append(result, &minecraftFlatpak)
-	} else if runtime.GOOS == "windows" {
-		if err := getScreenshotsFromPath(&minecraftStandalone, filepath.Join(os.Getenv("APPDATA"), ".minecraft/screenshots")); err != nil {
+func (p *MinecraftProvider) FindGames(ctx context.Context, options models.ProviderOptions) ([]*models.Game, error) {
+	var result []*models.Game
+
+	for _, variant := range getVariantsForOS() {
+		minecraftVariant := p.newGame(variant.name)
+		p.addScreenshotsFromPaths(minecraftVariant, variant.screenshotsPaths...)
+		result = append(result, minecraftVariant)
+	}
+
+	// Third party launchers keep a separate game directory per instance
+	for _, launcher := range getLaunchersForOS() {
+		instances, err := getLauncherInstances(launcher)
+		if err != nil {
 			p.logger.Error(err)
+			continue
 		}
-	} else if runtime.GOOS == "darwin" {
-		if err := getScreenshotsFromPath(&minecraftStandalone, filepath.Join(helpers.ExpandUser("~/Library/Application Support/minecraft/screenshots"))); err != nil {
-			p.logger.Error(err)
+
+		for _, instance := range instances {
+			minecraftInstance := p.newGame(launcher.name + " - " + instance.name)
+			p.addScreenshotsFromPaths(minecraftInstance, instance.screenshotsPath)
+			result = append(result, minecraftInstance)
 		}
 	}
-	result = append(result, &minecraftStandalone)
 
 	return result, nil
 }
 
+func (p *MinecraftProvider) Info() models.ProviderInfo {
+	return models.ProviderInfo{}
+}
+
+func (p *MinecraftProvider) Detect(options models.ProviderOptions) (string, error) {
+	var paths []string
+	for _, variant := range getVariantsForOS() {
+		paths = append(paths, variant.screenshotsPaths...)
+	}
+	for _, launcher := range getLaunchersForOS() {
+		paths = append(paths, launcher.instancesPath)
+	}
+	return helpers.FirstExistingPath(paths...)
+}
+
 func NewMinecraftProvider(logger *logrus.Logger, cache models.Cache) models.Provider {
 	return &MinecraftProvider{
 		logger: logger.WithField("from", "provider."+Name),
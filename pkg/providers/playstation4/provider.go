@@ -1,12 +1,14 @@
 package playstation4
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/cozy/goexif2/exif"
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,7 +19,7 @@ type Playstation4Provider struct {
 	logger *logrus.Entry
 }
 
-func (p *Playstation4Provider) FindGames(options models.ProviderOptions) ([]*models.Game, error) {
+func (p *Playstation4Provider) FindGames(ctx context.Context, options models.ProviderOptions) ([]*models.Game, error) {
 	var userGames []*models.Game
 
 	err := filepath.Walk(options.InputPath,
@@ -67,7 +69,7 @@ func (p *Playstation4Provider) FindGames(options models.ProviderOptions) ([]*mod
 				}
 
 				screenshot := models.Screenshot{Path: filePath, DestinationName: destinationName + extension}
-				userGames = models.AddScreenshotToGame(Name, userGames, gameName, screenshot)
+				userGames = models.AddScreenshotToGame(platformName, Name, userGames, gameName, screenshot)
 			}
 
 			return nil
@@ -78,6 +80,17 @@ func (p *Playstation4Provider) FindGames(options models.ProviderOptions) ([]*mod
 	return userGames, nil
 }
 
+func (p *Playstation4Provider) Info() models.ProviderInfo {
+	return models.ProviderInfo{
+		InputPath:         "Directory with the screenshots copied from the console",
+		InputPathRequired: true,
+	}
+}
+
+func (p *Playstation4Provider) Detect(options models.ProviderOptions) (string, error) {
+	return helpers.DetectInputPath(options)
+}
+
 func NewPlaystation4Provider(logger *logrus.Logger, cache models.Cache) models.Provider {
 	return &Playstation4Provider{
 		logger: logger.WithField("from", "provider."+Name),
@@ -1,12 +1,14 @@
 package playstation5
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,7 +22,7 @@ type Playstation5Provider struct {
 	logger *logrus.Entry
 }
 
-func (p *Playstation5Provider) FindGames(options models.ProviderOptions) ([]*models.Game, error) {
+func (p *Playstation5Provider) FindGames(ctx context.Context, options models.ProviderOptions) ([]*models.Game, error) {
 	var userGames []*models.Game
 
 	err := filepath.Walk(options.InputPath,
@@ -54,7 +56,7 @@ func (p *Playstation5Provider) FindGames(options models.ProviderOptions) ([]*mod
 				}
 
 				screenshot := models.Screenshot{Path: filePath, DestinationName: destinationName + extension}
-				userGames = models.AddScreenshotToGame(platformName, userGames, gameName, screenshot)
+				userGames = models.AddScreenshotToGame(platformName, Name, userGames, gameName, screenshot)
 			}
 
 			return nil
@@ -65,6 +67,17 @@ func (p *Playstation5Provider) FindGames(options models.ProviderOptions) ([]*mod
 	return userGames, nil
 }
 
+func (p *Playstation5Provider) Info() models.ProviderInfo {
+	return models.ProviderInfo{
+		InputPath:         "Directory with the screenshots copied from the console",
+		InputPathRequired: true,
+	}
+}
+
+func (p *Playstation5Provider) Detect(options models.ProviderOptions) (string, error) {
+	return helpers.DetectInputPath(options)
+}
+
 func NewPlaystation5Provider(logger *logrus.Logger, cache models.Cache) models.Provider {
 	return &Playstation5Provider{
 		logger: logger.WithField("from", "provider."+Name),
@@ -0,0 +1,44 @@
+package playstation5_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/playstation5"
+	"github.com/sirupsen/logrus"
+)
+
+// TestFindGamesProvider
+// Tests that games are reported with the registered provider and the
+// platform name of the console
+func TestFindGamesProvider(t *testing.T) {
+	inputPath := t.TempDir()
+	gamePath := filepath.Join(inputPath, "Astro's Playroom")
+	if err := os.Mkdir(gamePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gamePath, "Astro's Playroom_20221231235959.jpg"), []byte("jpg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := playstation5.NewPlaystation5Provider(logrus.New(), nil)
+	games, err := provider.FindGames(context.Background(), models.ProviderOptions{InputPath: inputPath})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("expected one game, got %d", len(games))
+	}
+	if games[0].Provider != playstation5.Name {
+		t.Errorf("expected provider %s, got %s", playstation5.Name, games[0].Provider)
+	}
+	if games[0].Platform != "PlayStation 5" {
+		t.Errorf("expected platform PlayStation 5, got %s", games[0].Platform)
+	}
+	if len(games[0].Screenshots) != 1 || games[0].Screenshots[0].DestinationName != "2022-12-31_23-59-59.jpg" {
+		t.Errorf("unexpected screenshots %+v", games[0].Screenshots)
+	}
+}
@@ -0,0 +1,140 @@
+package retroarch
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+const configFileName = "retroarch.cfg"
+
+// retroArchConfig holds the subset of retroarch.cfg settings the provider
+// needs to find playlists and screenshots.
+type retroArchConfig struct {
+	PlaylistDirectory        string
+	ScreenshotDirectory      string
+	ScreenshotsInContentDir  bool
+	SortScreenshotsByContent bool
+}
+
+// screenshotDirectories returns the directories where screenshots for the
+// provided content may be stored: the content directory if screenshots are
+// stored there or there's no global screenshot directory, and the global
+// screenshot directory otherwise. When sorting by content, the subfolder for
+// the content is returned before the global directory itself, which is always
+// included since sorting may have been enabled after taking screenshots.
+func (c retroArchConfig) screenshotDirectories(contentPath string) []string {
+	var result []string
+	contentDir := filepath.Dir(contentPath)
+
+	if c.ScreenshotsInContentDir || c.ScreenshotDirectory == "" {
+		result = append(result, contentDir)
+	}
+
+	if c.ScreenshotDirectory != "" {
+		if c.SortScreenshotsByContent {
+			result = append(result, filepath.Join(c.ScreenshotDirectory, filepath.Base(contentDir)))
+		}
+		result = append(result, c.ScreenshotDirectory)
+	}
+
+	return result
+}
+
+// specificity ranks how closely the folder of a screenshot is tied to the
+// content: its own folder, the global folder sorted by content or the global
+// folder shared by all the content.
+func (c retroArchConfig) specificity(contentPath, screenshotPath string) int {
+	directory := filepath.Dir(screenshotPath)
+	switch {
+	case directory == filepath.Dir(contentPath):
+		return 2
+	case c.ScreenshotDirectory != "" && directory != filepath.Clean(c.ScreenshotDirectory):
+		return 1
+	}
+	return 0
+}
+
+// resolveConfig returns the configuration to use from the provided input path,
+// which can point to a retroarch.cfg file, a directory holding one, or the
+// playlists directory for installations storing screenshots next to the
+// content.
+func resolveConfig(inputPath string) (retroArchConfig, error) {
+	inputPath = helpers.ExpandUser(inputPath)
+
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return retroArchConfig{}, fmt.Errorf("error reading input path: %s", err)
+	}
+
+	configPath := inputPath
+	if info.IsDir() {
+		configPath = filepath.Join(inputPath, configFileName)
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			return retroArchConfig{
+				PlaylistDirectory:       inputPath,
+				ScreenshotsInContentDir: true,
+			}, nil
+		}
+	}
+
+	return readConfig(configPath)
+}
+
+func readConfig(configPath string) (retroArchConfig, error) {
+	result := retroArchConfig{}
+
+	f, err := os.Open(configPath)
+	if err != nil {
+		return result, fmt.Errorf("error opening config file: %s", err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		values[strings.TrimSpace(parts[0])] = strings.Trim(strings.TrimSpace(parts[1]), `"`)
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("error reading config file: %s", err)
+	}
+
+	configDir := filepath.Dir(configPath)
+	result.PlaylistDirectory = configPathValue(configDir, values["playlist_directory"])
+	if result.PlaylistDirectory == "" {
+		result.PlaylistDirectory = filepath.Join(configDir, "playlists")
+	}
+	result.ScreenshotDirectory = configPathValue(configDir, values["screenshot_directory"])
+	result.ScreenshotsInContentDir = values["screenshots_in_content_dir"] == "true"
+	result.SortScreenshotsByContent = values["sort_screenshots_by_content_enable"] == "true"
+
+	return result, nil
+}
+
+// configPathValue expands a path setting from retroarch.cfg. RetroArch uses
+// "default" for unset paths and a ":" prefix for paths relative to its own
+// directory, which is where retroarch.cfg lives on portable installations.
+func configPathValue(configDir, value string) string {
+	if value == "" || value == "default" {
+		return ""
+	}
+
+	if strings.HasPrefix(value, ":") {
+		return filepath.Join(configDir, strings.TrimLeft(value[1:], `/\`))
+	}
+
+	return helpers.ExpandUser(value)
+}
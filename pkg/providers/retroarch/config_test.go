@@ -0,0 +1,93 @@
+package retroarch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
+)
+
+// TestConfigPathValue
+// Tests that config paths are resolved relative to the config folder or the
+// home folder.
+func TestConfigPathValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"default", ""},
+		{":/screenshots", filepath.Join("/config", "screenshots")},
+		{"/screenshots", "/screenshots"},
+		{"~/screenshots", helpers.ExpandUser("~/screenshots")},
+	}
+
+	for _, test := range tests {
+		if result := configPathValue("/config", test.value); result != test.expected {
+			t.Errorf("configPathValue(%q) = %q, expected %q", test.value, result, test.expected)
+		}
+	}
+}
+
+// TestReadConfig
+// Tests that the screenshot and playlist settings are read from the config.
+func TestReadConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected func(dir string) retroArchConfig
+	}{
+		{
+			name:     "defaults",
+			contents: "# comment\nvideo_fullscreen = \"true\"\n",
+			expected: func(dir string) retroArchConfig {
+				return retroArchConfig{PlaylistDirectory: filepath.Join(dir, "playlists")}
+			},
+		},
+		{
+			name:     "default values",
+			contents: "playlist_directory = \"default\"\nscreenshot_directory = \"default\"\n",
+			expected: func(dir string) retroArchConfig {
+				return retroArchConfig{PlaylistDirectory: filepath.Join(dir, "playlists")}
+			},
+		},
+		{
+			name: "custom",
+			contents: "playlist_directory = \"/playlists\"\n" +
+				"screenshot_directory = \":/screenshots\"\n" +
+				"screenshots_in_content_dir = \"true\"\n" +
+				"sort_screenshots_by_content_enable = \"true\"\n" +
+				"invalid line\n",
+			expected: func(dir string) retroArchConfig {
+				return retroArchConfig{
+					PlaylistDirectory:        "/playlists",
+					ScreenshotDirectory:      filepath.Join(dir, "screenshots"),
+					ScreenshotsInContentDir:  true,
+					SortScreenshotsByContent: true,
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		configPath := filepath.Join(dir, "retroarch.cfg")
+		if err := os.WriteFile(configPath, []byte(test.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := readConfig(configPath)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if expected := test.expected(dir); result != expected {
+			t.Errorf("%s: got %+v, expected %+v", test.name, result, expected)
+		}
+	}
+
+	if _, err := readConfig(filepath.Join(t.TempDir(), "missing.cfg")); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
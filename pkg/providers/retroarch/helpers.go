@@ -2,16 +2,16 @@ package retroarch
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"net/url"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/artwork"
 	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
 	"github.com/sirupsen/logrus"
 )
@@ -25,6 +25,53 @@ type retroArchPlaylistItem struct {
 	DBName   string `json:"db_name"`
 }
 
+// identity returns a key identifying the content of a playlist item, using
+// the database name and CRC32 when available so the same content listed in
+// several playlists is not taken as different games.
+func (item retroArchPlaylistItem) identity() string {
+	if key := item.crcKey(); key != "" {
+		return key
+	}
+	return "path:" + item.Path
+}
+
+// keys returns all the keys the content of a playlist item can be found by:
+// its path and its database and CRC32 when available, as the same content may
+// be listed with its CRC32 in one playlist and without it in another one.
+func (item retroArchPlaylistItem) keys() []string {
+	keys := []string{"path:" + item.Path}
+	if key := item.crcKey(); key != "" {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// crcKey returns the database and CRC32 key of the item, empty if the CRC32
+// is unknown.
+func (item retroArchPlaylistItem) crcKey() string {
+	crc := strings.ToUpper(strings.TrimSuffix(item.CRC32, "|crc"))
+	if crc == "" || crc == "DETECT" || crc == "00000000" {
+		return ""
+	}
+	return "crc:" + strings.TrimSuffix(item.DBName, ".lpl") + ":" + crc
+}
+
+// contentPath returns the path of the content file in the filesystem, which
+// for content inside archives (`game.zip#game.sfc`) is the archive itself.
+func (item retroArchPlaylistItem) contentPath() string {
+	return strings.SplitN(item.Path, "#", 2)[0]
+}
+
+// contentName returns the name RetroArch uses for screenshots of the item,
+// which for content inside archives is the name of the file in the archive.
+func (item retroArchPlaylistItem) contentName() string {
+	name := filepath.Base(item.Path)
+	if index := strings.LastIndex(item.Path, "#"); index >= 0 {
+		name = item.Path[index+1:]
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
 type retroArchPlaylist struct {
 	Version            string                  `json:"version"`
 	DefaultCorePath    string                  `json:"default_core_path"`
@@ -36,22 +83,28 @@ type retroArchPlaylist struct {
 	Items              []retroArchPlaylistItem `json:"items"`
 }
 
-func formatLibretroBoxartURL(platform string, game string) string {
-	return libretroCoverURLBase + url.PathEscape(path.Join(platform, "Named_Boxarts", game)) + ".png"
-}
+var errNotAScreenshot = errors.New("not a screenshot")
 
-func cleanPlatformName(platformName string) string {
-	// Removes the "Nintendo - " portion of nintendo systems
-	// Could probably be extended to others (Sony, Microsoft) by removing all until the first hyphen
-	if strings.Contains(platformName, "Nintendo") {
-		return strings.Replace(platformName, "Nintendo - ", "", 1)
+// libretroCovers returns the thumbnails available for a playlist item. The
+// label is tried first, falling back to the content name, which on properly
+// named collections matches the database name.
+func libretroCovers(playlistName string, item retroArchPlaylistItem) []models.Cover {
+	system := playlistName
+	if item.DBName != "" {
+		system = strings.TrimSuffix(item.DBName, ".lpl")
 	}
-	return platformName
+
+	names := []string{item.Label}
+	if contentName := item.contentName(); contentName != item.Label {
+		names = append(names, contentName)
+	}
+
+	return artwork.LibretroCovers(system, names...)
 }
 
 func cleanGameName(gameName string) string {
 	splits := strings.Split(gameName, "(")
-	return splits[0]
+	return strings.TrimSpace(splits[0])
 }
 
 func readPlaylists(logger *logrus.Entry, playlistsPath string) (map[string]retroArchPlaylist, error) {
@@ -90,43 +143,118 @@ func readPlaylists(logger *logrus.Entry, playlistsPath string) (map[string]retro
 	return result, nil
 }
 
-func findScreenshotsForGame(logger *logrus.Entry, item retroArchPlaylistItem) ([]models.Screenshot, error) {
-	var result []models.Screenshot
-	filePath := filepath.Dir(item.Path)
-	fileName := strings.Replace(filepath.Base(item.Path), filepath.Ext(item.Path), "", 1)
-	files, err := ioutil.ReadDir(filePath)
+// contentScreenshots holds the screenshots found in a directory, by the name
+// of the content they were taken for.
+type contentScreenshots map[string][]models.Screenshot
+
+// screenshotLister lists each directory only once, as the global screenshot
+// directory is shared by all the playlist items.
+type screenshotLister struct {
+	logger      *logrus.Entry
+	directories map[string]contentScreenshots
+}
+
+func newScreenshotLister(logger *logrus.Entry) *screenshotLister {
+	return &screenshotLister{
+		logger:      logger,
+		directories: make(map[string]contentScreenshots),
+	}
+}
+
+// list returns the screenshots in the directory, which is empty if it doesn't
+// exist.
+func (l *screenshotLister) list(directory string) (contentScreenshots, error) {
+	if screenshots, exists := l.directories[directory]; exists {
+		return screenshots, nil
+	}
+
+	screenshots := make(contentScreenshots)
+	if _, err := os.Stat(directory); os.IsNotExist(err) {
+		l.directories[directory] = screenshots
+		return screenshots, nil
+	}
+
+	files, err := ioutil.ReadDir(directory)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, file := range files {
-		// Get all screenshots for the game, excluding state screenshots
-		if !file.IsDir() && strings.Contains(file.Name(), fileName) && strings.Contains(file.Name(), ".png") {
-			if strings.Contains(file.Name(), ".state.") {
-				// Ignore state screenshots
-				continue
-			}
+		if file.IsDir() {
+			continue
+		}
 
-			extension := filepath.Ext(file.Name())
-			var screenshotDestinationName string
-
-			// Handle autoamtic achievement screenshots: get datetime from modtime
-			if strings.Contains(file.Name(), "-cheevo-") {
-				filenameParts := strings.Split(file.Name(), "-")
-				achievementID := strings.Replace(filenameParts[len(filenameParts)-1], extension, "", 1)
-				screenshotDestinationName = file.ModTime().Format(models.DatetimeFormat) + "_retroachievement-" + achievementID + extension
-			} else {
-				screenshotDate, err := time.Parse(datetimeLayout, file.Name()[len(file.Name())-len(extension)-len(datetimeLayout):len(file.Name())-len(extension)])
-				if err == nil {
-					screenshotDestinationName = screenshotDate.Format(models.DatetimeFormat) + extension
-				} else {
-					logger.Errorf("Error formatting screenshot %s: %s", file.Name(), err)
-					continue
-				}
+		contentName, screenshotDestinationName, err := parseScreenshotName(file)
+		if err != nil {
+			if !errors.Is(err, errNotAScreenshot) {
+				l.logger.Errorf("Error formatting screenshot %s: %s", file.Name(), err)
 			}
+			continue
+		}
+
+		screenshots[contentName] = append(screenshots[contentName], models.Screenshot{Path: filepath.Join(directory, file.Name()), DestinationName: screenshotDestinationName})
+	}
 
-			result = append(result, models.Screenshot{Path: filepath.Join(filePath, file.Name()), DestinationName: screenshotDestinationName})
+	l.directories[directory] = screenshots
+	return screenshots, nil
+}
+
+// findScreenshotsForGame returns the screenshots taken for the exact content
+// of the item in the directories.
+func findScreenshotsForGame(lister *screenshotLister, item retroArchPlaylistItem, directories []string) ([]models.Screenshot, error) {
+	var result []models.Screenshot
+	fileName := item.contentName()
+	seen := make(map[string]bool)
+
+	for _, directory := range directories {
+		if seen[directory] {
+			continue
 		}
+		seen[directory] = true
+
+		screenshots, err := lister.list(directory)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, screenshots[fileName]...)
 	}
+
 	return result, nil
 }
+
+// parseScreenshotName returns the name of the content a screenshot was taken
+// for along with its destination name, based on the names RetroArch gives to
+// screenshots: `<content>-<datetime>.png` for the regular ones and
+// `<content>-cheevo-<achievement id>.png` for the achievement ones.
+// State screenshots and other files return errNotAScreenshot.
+func parseScreenshotName(file os.FileInfo) (contentName, destinationName string, err error) {
+	extension := filepath.Ext(file.Name())
+	if extension != ".png" {
+		return "", "", errNotAScreenshot
+	}
+
+	baseName := strings.TrimSuffix(file.Name(), extension)
+
+	// Ignore state screenshots
+	if strings.Contains(baseName, ".state") {
+		return "", "", errNotAScreenshot
+	}
+
+	// Handle automatic achievement screenshots: get datetime from modtime
+	if index := strings.LastIndex(baseName, "-cheevo-"); index > 0 {
+		achievementID := baseName[index+len("-cheevo-"):]
+		return baseName[:index], file.ModTime().Format(models.DatetimeFormat) + "_retroachievement-" + achievementID + extension, nil
+	}
+
+	index := len(baseName) - len(datetimeLayout) - 1
+	if index <= 0 || baseName[index] != '-' {
+		return "", "", fmt.Errorf("file name does not follow the datetime convention")
+	}
+
+	screenshotDate, err := time.Parse(datetimeLayout, baseName[index+1:])
+	if err != nil {
+		return "", "", err
+	}
+
+	return baseName[:index], screenshotDate.Format(models.DatetimeFormat) + extension, nil
+}
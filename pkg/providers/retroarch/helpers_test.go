@@ -0,0 +1,108 @@
+package retroarch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+)
+
+// TestPlaylistItemIdentity
+// Tests that content is identified by its database and CRC32 when known and
+// by its path otherwise.
+func TestPlaylistItemIdentity(t *testing.T) {
+	tests := []struct {
+		item     retroArchPlaylistItem
+		expected string
+	}{
+		{retroArchPlaylistItem{Path: "/roms/game.sfc", CRC32: "a31bead4|crc", DBName: "Nintendo - SNES.lpl"}, "crc:Nintendo - SNES:A31BEAD4"},
+		{retroArchPlaylistItem{Path: "/roms/game.sfc", CRC32: "A31BEAD4"}, "crc::A31BEAD4"},
+		{retroArchPlaylistItem{Path: "/roms/game.sfc", CRC32: "DETECT", DBName: "Nintendo - SNES.lpl"}, "path:/roms/game.sfc"},
+		{retroArchPlaylistItem{Path: "/roms/game.sfc", CRC32: "00000000|crc"}, "path:/roms/game.sfc"},
+		{retroArchPlaylistItem{Path: "/roms/game.sfc"}, "path:/roms/game.sfc"},
+	}
+
+	for _, test := range tests {
+		if result := test.item.identity(); result != test.expected {
+			t.Errorf("identity() of %+v = %q, expected %q", test.item, result, test.expected)
+		}
+	}
+}
+
+// TestPlaylistItemContentName
+// Tests that the content name is the file name without extension, using the
+// file inside the archive when there's one.
+func TestPlaylistItemContentName(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/roms/Game (USA).sfc", "Game (USA)"},
+		{"/roms/Game (USA).zip#Game (USA).sfc", "Game (USA)"},
+		{"/roms/game.zip#inner/Game.sfc", "inner/Game"},
+		{"/roms/Game", "Game"},
+	}
+
+	for _, test := range tests {
+		item := retroArchPlaylistItem{Path: test.path}
+		if result := item.contentName(); result != test.expected {
+			t.Errorf("contentName() of %q = %q, expected %q", test.path, result, test.expected)
+		}
+	}
+}
+
+// TestParseScreenshotName
+// Tests that the content and destination names are parsed from the names
+// RetroArch gives to screenshots.
+func TestParseScreenshotName(t *testing.T) {
+	modTime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		name        string
+		content     string
+		destination string
+		err         error
+	}{
+		{"Game (USA)-210102-030405.png", "Game (USA)", "2021-01-02_03-04-05.png", nil},
+		{"Game-Name-210102-030405.png", "Game-Name", "2021-01-02_03-04-05.png", nil},
+		{"Game (USA)-cheevo-1234.png", "Game (USA)", modTime.Local().Format(models.DatetimeFormat) + "_retroachievement-1234.png", nil},
+		{"Game (USA).state1.png", "", "", errNotAScreenshot},
+		{"Game (USA).jpg", "", "", errNotAScreenshot},
+		{"Game.png", "", "", errors.New("invalid")},
+		{"Game-219999-999999.png", "", "", errors.New("invalid")},
+	}
+
+	dir := t.TempDir()
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, destination, err := parseScreenshotName(info)
+		if test.err != nil {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			} else if errors.Is(test.err, errNotAScreenshot) && !errors.Is(err, errNotAScreenshot) {
+				t.Errorf("%s: expected errNotAScreenshot, got %s", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if content != test.content || destination != test.destination {
+			t.Errorf("%s: got (%q, %q), expected (%q, %q)", test.name, content, destination, test.content, test.destination)
+		}
+	}
+}
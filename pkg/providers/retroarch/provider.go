@@ -1,60 +1,233 @@
 // RetroArch screenshot provider
 
 // Notes:
-// This provider only works if the following retroarch configuration is set:
-// screenshots_in_content_dir = "true"
+// This provider requires the following retroarch configuration to be set:
 // auto_screenshot_filename = "true"
-// This way the screenshots will be stored in the same folders as the games
+// So screenshots are named by retroarch for us to parse them properly.
 // We will read the playlists from retroarch to determine the Platforms and games
-// from there, and screenshots will be extracted from the content folders, so you can
-// sort your games the way you like most, but screenshots need to be renamed
-// by retroarch for us to parse them properly.
+// from there.
+// If the input path points to retroarch.cfg (or the folder holding it) the
+// playlist and screenshot directories are read from it, supporting both
+// screenshots stored next to the content (screenshots_in_content_dir = "true")
+// and on the global screenshot directory, sorted by content or not
+// (sort_screenshots_by_content_enable).
+// Otherwise the input path is used as the playlists folder and screenshots
+// are expected to be stored in the same folders as the games.
 
 package retroarch
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
 	"github.com/sirupsen/logrus"
 )
 
 const Name = "retroarch"
 
-const libretroCoverURLBase = "http://thumbnails.libretro.com/"
 const datetimeLayout = "060102-150405"
 
 type RetroArchProvider struct {
 	logger *logrus.Entry
 }
 
-func (p *RetroArchProvider) FindGames(options models.ProviderOptions) ([]*models.Game, error) {
+func (p *RetroArchProvider) FindGames(ctx context.Context, options models.ProviderOptions) ([]*models.Game, error) {
 	var userGames []*models.Game
 
-	playlists, err := readPlaylists(p.logger, options.InputPath)
+	config, err := resolveConfig(options.InputPath)
 	if err != nil {
 		return nil, err
 	}
 
+	playlists, err := readPlaylists(p.logger, config.PlaylistDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collection playlists (history, favourites, etc.) mostly hold content
+	// from other playlists, so they are read last to import only the content
+	// missing from the system playlists.
+	playlistNames := make([]string, 0, len(playlists))
 	for playlistName := range playlists {
+		playlistNames = append(playlistNames, playlistName)
+	}
+	sort.Slice(playlistNames, func(i, j int) bool {
+		iCollection, jCollection := isCollectionPlaylist(playlistNames[i]), isCollectionPlaylist(playlistNames[j])
+		if iCollection != jCollection {
+			return jCollection
+		}
+		return playlistNames[i] < playlistNames[j]
+	})
+
+	// Games found, by all the keys of their content so the same content
+	// listed in several playlists (or stored in several places) is only
+	// imported once.
+	games := make(map[string]*models.Game)
+	// Games claiming each screenshot path.
+	claims := make(map[string][]screenshotClaim)
+	lister := newScreenshotLister(p.logger)
+
+	for _, playlistName := range playlistNames {
 		for _, item := range playlists[playlistName].Items {
-			screenshots, err := findScreenshotsForGame(p.logger, item)
+			keys := item.keys()
+			pathKey := keys[0]
+			if game, exists := games[pathKey]; exists {
+				for _, key := range keys[1:] {
+					if _, exists := games[key]; !exists {
+						games[key] = game
+					}
+				}
+				continue
+			}
+
+			var game *models.Game
+			for _, key := range keys[1:] {
+				if existing, exists := games[key]; exists {
+					game = existing
+					break
+				}
+			}
+
+			platform := playlistName
+			if game != nil {
+				platform = game.Platform
+			} else if isCollectionPlaylist(playlistName) {
+				platform = strings.TrimSuffix(item.DBName, ".lpl")
+				if platform == "" {
+					p.logger.Warnf("Skipping %s from %s, its system is unknown", item.Label, playlistName)
+					continue
+				}
+			}
+
+			directories := config.screenshotDirectories(item.contentPath())
+			screenshots, err := findScreenshotsForGame(lister, item, directories)
 			if err != nil {
 				p.logger.Errorf("Error retrieving game screenshots: %s", err)
 				continue
 			}
 
-			userGames = append(userGames, &models.Game{
-				Platform:    cleanPlatformName(playlistName),
-				Name:        cleanGameName(item.Label),
-				Provider:    Name,
-				Screenshots: screenshots,
-				CoverURL:    formatLibretroBoxartURL(playlistName, item.Label),
-			})
+			if game == nil {
+				game = &models.Game{
+					ID:       item.identity(),
+					Platform: platform,
+					Name:     cleanGameName(item.Label),
+					Label:    item.Label,
+					Provider: Name,
+					Covers:   libretroCovers(platform, item),
+				}
+				userGames = append(userGames, game)
+			}
+			for _, key := range keys {
+				if _, exists := games[key]; !exists {
+					games[key] = game
+				}
+			}
+
+			for _, screenshot := range screenshots {
+				specificity := config.specificity(item.contentPath(), screenshot.Path)
+				if claim := findClaim(claims[screenshot.Path], game); claim != nil {
+					// Another copy of the same content found it already
+					if specificity > claim.specificity {
+						claim.specificity = specificity
+					}
+					continue
+				}
+
+				game.Screenshots = append(game.Screenshots, screenshot)
+				claims[screenshot.Path] = append(claims[screenshot.Path], screenshotClaim{
+					game:        game,
+					specificity: specificity,
+				})
+			}
+		}
+	}
+
+	// Screenshots matching content from different games are kept by the one
+	// whose folder they are stored in. If that doesn't tell them apart they
+	// are reported instead of being imported into the wrong game.
+	for path, pathClaims := range claims {
+		if len(pathClaims) < 2 {
+			continue
+		}
+
+		sort.SliceStable(pathClaims, func(i, j int) bool {
+			return pathClaims[i].specificity > pathClaims[j].specificity
+		})
+
+		if pathClaims[0].specificity > pathClaims[1].specificity {
+			for _, claim := range pathClaims[1:] {
+				removeScreenshot(claim.game, path)
+			}
+			continue
+		}
+
+		var names []string
+		for _, claim := range pathClaims {
+			names = append(names, fmt.Sprintf("%s (%s)", claim.game.Name, claim.game.Platform))
+		}
+		p.logger.Warnf("Skipping ambiguous screenshot %s, matches: %s", path, strings.Join(names, ", "))
+
+		for _, claim := range pathClaims {
+			removeScreenshot(claim.game, path)
 		}
 	}
 
 	return userGames, nil
 }
 
+// screenshotClaim is a game whose content screenshots include a file.
+type screenshotClaim struct {
+	game        *models.Game
+	specificity int
+}
+
+// findClaim returns the claim of the game, nil if it hasn't claimed the file.
+func findClaim(claims []screenshotClaim, game *models.Game) *screenshotClaim {
+	for index := range claims {
+		if claims[index].game == game {
+			return &claims[index]
+		}
+	}
+	return nil
+}
+
+// isCollectionPlaylist checks if the playlist is one of the collections
+// RetroArch keeps (history, favourites, etc.) instead of a system playlist.
+func isCollectionPlaylist(playlistName string) bool {
+	return strings.HasPrefix(playlistName, "content_")
+}
+
+func removeScreenshot(game *models.Game, path string) {
+	screenshots := game.Screenshots[:0]
+	for _, screenshot := range game.Screenshots {
+		if screenshot.Path != path {
+			screenshots = append(screenshots, screenshot)
+		}
+	}
+	game.Screenshots = screenshots
+}
+
+func (p *RetroArchProvider) Info() models.ProviderInfo {
+	return models.ProviderInfo{
+		InputPath:         "retroarch.cfg, the directory holding it or the playlists directory",
+		InputPathRequired: true,
+	}
+}
+
+func (p *RetroArchProvider) Detect(options models.ProviderOptions) (string, error) {
+	if options.InputPath == "" {
+		return "", models.ErrInputPathRequired
+	}
+	config, err := resolveConfig(options.InputPath)
+	if err != nil {
+		return "", err
+	}
+	return config.PlaylistDirectory, nil
+}
+
 func NewRetroArchProvider(logger *logrus.Logger, cache models.Cache) models.Provider {
 	return &RetroArchProvider{
 		logger: logger.WithField("from", "provider."+Name),
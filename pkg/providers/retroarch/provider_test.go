@@ -0,0 +1,114 @@
+package retroarch_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/retroarch"
+	"github.com/sirupsen/logrus"
+)
+
+type playlistItem struct {
+	Path   string `json:"path"`
+	Label  string `json:"label"`
+	CRC32  string `json:"crc32"`
+	DBName string `json:"db_name"`
+}
+
+func writePlaylist(t *testing.T, path string, items ...playlistItem) {
+	contents, err := json.Marshal(map[string]interface{}{"version": "1.5", "items": items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFiles(t *testing.T, paths ...string) {
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func findGames(t *testing.T, inputPath string) []*models.Game {
+	provider := retroarch.NewRetroArchProvider(logrus.New(), nil)
+	games, err := provider.FindGames(context.Background(), models.ProviderOptions{InputPath: inputPath})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return games
+}
+
+func screenshotNames(game *models.Game) []string {
+	var names []string
+	for _, screenshot := range game.Screenshots {
+		names = append(names, filepath.Base(screenshot.Path))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestFindGamesSameContentPath
+// Tests that content listed without its CRC32 in a playlist and with it in
+// another one is a single game keeping its screenshots
+func TestFindGamesSameContentPath(t *testing.T) {
+	root := t.TempDir()
+	playlists := filepath.Join(root, "playlists")
+	rom := filepath.Join(root, "roms", "Game (USA).sfc")
+	writeFiles(t, rom, filepath.Join(root, "roms", "Game (USA)-230101-120000.png"))
+	if err := os.Mkdir(playlists, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writePlaylist(t, filepath.Join(playlists, "Nintendo - SNES.lpl"), playlistItem{Path: rom, Label: "Game (USA)", CRC32: "DETECT", DBName: "Nintendo - SNES.lpl"})
+	writePlaylist(t, filepath.Join(playlists, "content_history.lpl"), playlistItem{Path: rom, Label: "Game (USA)", CRC32: "A31BEAD4|crc", DBName: "Nintendo - SNES.lpl"})
+
+	games := findGames(t, playlists)
+	if len(games) != 1 {
+		t.Fatalf("expected one game, got %d", len(games))
+	}
+	if names := screenshotNames(games[0]); len(names) != 1 {
+		t.Errorf("expected the screenshot to be kept, got %v", names)
+	}
+}
+
+// TestFindGamesSameCRC
+// Tests that copies of the same content in different folders are a single
+// game with the screenshots of all the copies
+func TestFindGamesSameCRC(t *testing.T) {
+	root := t.TempDir()
+	playlists := filepath.Join(root, "playlists")
+	first := filepath.Join(root, "roms", "Game (USA).sfc")
+	second := filepath.Join(root, "backup", "Game (USA).sfc")
+	writeFiles(t, first, second,
+		filepath.Join(root, "roms", "Game (USA)-230101-120000.png"),
+		filepath.Join(root, "backup", "Game (USA)-230102-120000.png"),
+	)
+	if err := os.Mkdir(playlists, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writePlaylist(t, filepath.Join(playlists, "Nintendo - SNES.lpl"),
+		playlistItem{Path: first, Label: "Game (USA)", CRC32: "A31BEAD4|crc", DBName: "Nintendo - SNES.lpl"},
+		playlistItem{Path: second, Label: "Game (USA)", CRC32: "A31BEAD4|crc", DBName: "Nintendo - SNES.lpl"},
+	)
+
+	games := findGames(t, playlists)
+	if len(games) != 1 {
+		t.Fatalf("expected one game, got %d", len(games))
+	}
+	if names := screenshotNames(games[0]); len(names) != 2 {
+		t.Errorf("expected the screenshots of both copies, got %v", names)
+	}
+}
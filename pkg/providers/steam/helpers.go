@@ -1,6 +1,7 @@
 package steam
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,52 +33,184 @@ func getBasePathForOS() (string, error) {
 	return path, nil
 }
 
-func getSteamAppList(logger *logrus.Entry, cache models.Cache, c chan SteamAppList) {
-	defer close(c)
-	cacheKey := "steam-applist"
-	download := true
-	var payload []byte
+const (
+	appListCacheKey      = "steam-applist"
+	appListIndexCacheKey = "steam-applist-index"
+	appListNamespace     = "apps"
+	appListExpiration    = 24 * time.Hour
+)
 
-	result, err := cache.GetExpiry(cacheKey, 24*time.Hour)
-	if err != nil && !errors.Is(err, models.ErrCacheKeyDontExist) {
-		logger.Errorf("error retrieving cache: %s", err)
-		return
+func downloadSteamAppList(ctx context.Context) ([]byte, error) {
+	response, err := helpers.DoRequest(ctx, "GET", gameListURL)
+	if err != nil {
+		return nil, fmt.Errorf("error making request for Steam APP List: %w", err)
 	}
+	defer response.Body.Close()
 
-	if len(result) > 0 {
-		download = false
-		payload = []byte(result)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status code %d for Steam APP List", response.StatusCode)
 	}
 
-	if download {
-		response, err := helpers.DoRequest("GET", gameListURL)
-		if err != nil {
-			logger.Errorf("Error making request for Steam APP List: %s", err)
-		}
+	payload, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading steam response: %s", err)
+	}
+
+	return payload, nil
+}
+
+func parseSteamAppList(payload []byte) (SteamAppList, error) {
+	steamListResponse := SteamAppListResponse{}
+	if err := json.Unmarshal(payload, &steamListResponse); err != nil {
+		return steamListResponse.AppList, fmt.Errorf("error unmarshalling steam's response: %s", err)
+	}
+	return steamListResponse.AppList, nil
+}
 
-		if response.Body != nil {
-			defer response.Body.Close()
+// fetchSteamAppList downloads and parses the Steam app list.
+func fetchSteamAppList(ctx context.Context) ([]SteamApp, error) {
+	payload, err := downloadSteamAppList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appList, err := parseSteamAppList(payload)
+	if err != nil {
+		return nil, err
+	}
+	return appList.Apps, nil
+}
+
+// encodeAppList returns the compact form of the app list stored in the cache,
+// a line per app with its ID and name separated by a tab, which is much
+// faster to read than the JSON returned by Steam.
+func encodeAppList(apps []SteamApp) string {
+	var builder strings.Builder
+	for _, app := range apps {
+		builder.WriteString(strconv.FormatUint(app.AppID, 10))
+		builder.WriteByte('\t')
+		builder.WriteString(appNameReplacer.Replace(app.Name))
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+var appNameReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+// decodeAppList reads an app list stored in the cache, which may also be the
+// JSON returned by Steam as stored by older versions.
+func decodeAppList(value string) ([]SteamApp, error) {
+	if strings.HasPrefix(value, "{") {
+		appList, err := parseSteamAppList([]byte(value))
+		return appList.Apps, err
+	}
+
+	apps := make([]SteamApp, 0, strings.Count(value, "\n"))
+	for len(value) > 0 {
+		var line string
+		if index := strings.IndexByte(value, '\n'); index >= 0 {
+			line, value = value[:index], value[index+1:]
+		} else {
+			line, value = value, ""
 		}
 
-		payload, err = ioutil.ReadAll(response.Body)
+		id, name, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		appID, err := strconv.ParseUint(id, 10, 64)
 		if err != nil {
-			logger.Errorf("Error reading steam response: %s", err)
+			return nil, fmt.Errorf("error reading cached steam app list: %s", err)
+		}
+		apps = append(apps, SteamApp{AppID: appID, Name: name})
+	}
+	return apps, nil
+}
+
+// isFresh returns whether the cached key was stored before it expired.
+func isFresh(cache models.Cache, key string) bool {
+	metadata, err := cache.GetMetadata(key)
+	return err == nil && time.Since(metadata.StoredAt) < appListExpiration
+}
+
+// logStale logs that an expired app list is used because it couldn't be
+// downloaded, which is expected in offline mode.
+func logStale(logger *logrus.Entry, err error) {
+	if errors.Is(err, helpers.ErrOffline) {
+		logger.Debug("Offline, using cached steam app list")
+		return
+	}
+	logger.Warnf("Using expired steam app list: %s", err)
+}
+
+// getSteamAppList returns the Steam app list, indexed by ID. Caches able to
+// store records in batches keep a record per app, otherwise the whole list is
+// cached in a compact form. An expired list is used if a new one can't be
+// downloaded.
+func getSteamAppList(ctx context.Context, logger *logrus.Entry, cache models.Cache) (AppFinder, error) {
+	if index, ok := cache.Namespace(appListNamespace).(models.BatchCache); ok {
+		return getIndexedSteamAppList(ctx, logger, cache, index)
+	}
+
+	cached, err := cache.Get(appListCacheKey)
+	if err != nil && !errors.Is(err, models.ErrCacheKeyDontExist) {
+		return nil, fmt.Errorf("error retrieving cache: %s", err)
+	}
+
+	if len(cached) == 0 || !isFresh(cache, appListCacheKey) {
+		apps, err := fetchSteamAppList(ctx)
+		switch {
+		case err == nil:
+			if err := cache.PutWithMetadata(appListCacheKey, encodeAppList(apps), models.CacheMetadata{SourceURL: gameListURL}); err != nil {
+				logger.Error(err)
+			}
+			return NewAppIndex(apps), nil
+		case len(cached) > 0 && ctx.Err() == nil:
+			logStale(logger, err)
+		default:
+			return nil, err
 		}
 	}
 
-	if download {
-		if err := cache.Put(cacheKey, string(payload)); err != nil {
-			logger.Error(err)
+	apps, err := decodeAppList(cached)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAppIndex(apps), nil
+}
+
+// getIndexedSteamAppList returns the app list stored as records in the index
+// cache, refreshing them if expired.
+func getIndexedSteamAppList(ctx context.Context, logger *logrus.Entry, cache models.Cache, index models.BatchCache) (AppFinder, error) {
+	if isFresh(cache, appListIndexCacheKey) {
+		return &indexedSteamAppList{cache: index}, nil
+	}
+
+	apps, err := fetchSteamAppList(ctx)
+	if err != nil {
+		if _, cacheErr := cache.Get(appListIndexCacheKey); cacheErr == nil && ctx.Err() == nil {
+			logStale(logger, err)
+			return &indexedSteamAppList{cache: index}, nil
 		}
+		return nil, err
 	}
 
-	steamListResponse := SteamAppListResponse{}
-	jsonErr := json.Unmarshal(payload, &steamListResponse)
-	if jsonErr != nil {
-		logger.Errorf("Error unmarshalling steam's response: %s", jsonErr)
+	records := make(map[string]string, len(apps))
+	for _, app := range apps {
+		records[strconv.FormatUint(app.AppID, 10)] = app.Name
+	}
+
+	metadata := models.CacheMetadata{SourceURL: gameListURL}
+	if err := index.PutBatch(records, metadata); err != nil {
+		logger.Errorf("error storing steam app list records: %s", err)
+		// The list is already in memory, use it for this run
+		return NewAppIndex(apps), nil
+	}
+	if err := cache.PutWithMetadata(appListIndexCacheKey, strconv.Itoa(len(records)), metadata); err != nil {
+		logger.Error(err)
 	}
 
-	c <- steamListResponse.AppList
+	return &indexedSteamAppList{cache: index}, nil
 }
 
 func guessUsers(basePath string) ([]string, error) {
@@ -0,0 +1,203 @@
+package steam
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/identity"
+)
+
+// Match is the kind of match used when looking up apps by name.
+type Match int
+
+const (
+	// MatchExact only matches the exact name.
+	MatchExact Match = iota
+	// MatchCaseInsensitive matches names ignoring case, falling back from an
+	// exact match.
+	MatchCaseInsensitive
+	// MatchFuzzy matches names with the same identity key or similar enough,
+	// falling back from a case insensitive match.
+	MatchFuzzy
+)
+
+// fuzzyMinSimilarity is the minimum similarity (0 to 1) between identity keys
+// for a fuzzy match.
+const fuzzyMinSimilarity = 0.8
+
+// AppFinder finds Steam apps by their ID or name.
+type AppFinder interface {
+	FindID(id string) (SteamApp, error)
+	// FindName returns the apps matching the name, best matches first.
+	FindName(name string, match Match) ([]SteamApp, error)
+}
+
+// AppIndex indexes a Steam app list by ID and name. The name indexes are only
+// built when first searching by name.
+type AppIndex struct {
+	apps []SteamApp
+	byID map[uint64]int
+
+	namesOnce sync.Once
+	byName    map[string][]int
+	byLower   map[string][]int
+	byKey     map[string][]int
+	keys      []string
+	byWord    map[string][]int
+}
+
+func (index *AppIndex) FindID(id string) (result SteamApp, err error) {
+	uintGameID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return result, errGameIDNotFound
+	}
+
+	if i, exists := index.byID[uintGameID]; exists {
+		return index.apps[i], nil
+	}
+	return result, errGameIDNotFound
+}
+
+func (index *AppIndex) buildNames() {
+	index.byName = make(map[string][]int, len(index.apps))
+	index.byLower = make(map[string][]int, len(index.apps))
+	index.byKey = make(map[string][]int, len(index.apps))
+	index.keys = make([]string, len(index.apps))
+	index.byWord = make(map[string][]int)
+
+	for i, app := range index.apps {
+		key := identity.Key(app.Name)
+		index.keys[i] = key
+		index.byName[app.Name] = append(index.byName[app.Name], i)
+		index.byLower[strings.ToLower(app.Name)] = append(index.byLower[strings.ToLower(app.Name)], i)
+		index.byKey[key] = append(index.byKey[key], i)
+		for _, word := range uniqueWords(key) {
+			index.byWord[word] = append(index.byWord[word], i)
+		}
+	}
+}
+
+func (index *AppIndex) FindName(name string, match Match) ([]SteamApp, error) {
+	index.namesOnce.Do(index.buildNames)
+
+	if found := index.byName[name]; len(found) > 0 || match == MatchExact {
+		return index.appsAt(found), nil
+	}
+	if found := index.byLower[strings.ToLower(name)]; len(found) > 0 || match == MatchCaseInsensitive {
+		return index.appsAt(found), nil
+	}
+
+	key := identity.Key(name)
+	if found := index.byKey[key]; len(found) > 0 {
+		return index.appsAt(found), nil
+	}
+	return index.appsAt(index.similar(key)), nil
+}
+
+// similar returns the apps with keys similar to the provided one, most
+// similar first. Only apps sharing at least half of the words are compared.
+func (index *AppIndex) similar(key string) []int {
+	words := uniqueWords(key)
+	if len(words) == 0 {
+		return nil
+	}
+
+	shared := make(map[int]int)
+	for _, word := range words {
+		for _, i := range index.byWord[word] {
+			shared[i]++
+		}
+	}
+
+	scores := make(map[int]float64)
+	var result []int
+	for i, count := range shared {
+		if count*2 < len(words) {
+			continue
+		}
+		if score := similarity(key, index.keys[i]); score >= fuzzyMinSimilarity {
+			scores[i] = score
+			result = append(result, i)
+		}
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		if scores[result[a]] != scores[result[b]] {
+			return scores[result[a]] > scores[result[b]]
+		}
+		return index.apps[result[a]].AppID < index.apps[result[b]].AppID
+	})
+	return result
+}
+
+func (index *AppIndex) appsAt(positions []int) []SteamApp {
+	result := make([]SteamApp, 0, len(positions))
+	for _, i := range positions {
+		result = append(result, index.apps[i])
+	}
+	return result
+}
+
+func uniqueWords(key string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, word := range strings.Fields(key) {
+		if !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+// similarity returns how similar two strings are, from 0 to 1, based on
+// their Levenshtein distance.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+// NewAppIndex returns an index of the provided apps.
+func NewAppIndex(apps []SteamApp) *AppIndex {
+	index := &AppIndex{
+		apps: apps,
+		byID: make(map[uint64]int, len(apps)),
+	}
+	for i, app := range apps {
+		index.byID[app.AppID] = i
+	}
+	return index
+}
@@ -0,0 +1,67 @@
+package steam_test
+
+import (
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/steam"
+)
+
+var testApps = []steam.SteamApp{
+	{AppID: 1245620, Name: "ELDEN RING"},
+	{AppID: 292030, Name: "The Witcher® 3: Wild Hunt"},
+	{AppID: 499450, Name: "The Witcher 3: Wild Hunt - Game of the Year Edition"},
+	{AppID: 1174180, Name: "Red Dead Redemption 2"},
+	{AppID: 1245621, Name: "Elden Ring"},
+}
+
+// TestAppIndexFindID
+// Tests that apps are found by their ID
+func TestAppIndexFindID(t *testing.T) {
+	index := steam.NewAppIndex(testApps)
+
+	app, err := index.FindID("1174180")
+	if err != nil || app.Name != "Red Dead Redemption 2" {
+		t.Errorf("FindID(1174180) = %v, %v", app, err)
+	}
+
+	for _, id := range []string{"1", "not-an-id"} {
+		if _, err := index.FindID(id); err == nil {
+			t.Errorf("FindID(%s) should fail", id)
+		}
+	}
+}
+
+// TestAppIndexFindName
+// Tests that each kind of match falls back from the stricter ones
+func TestAppIndexFindName(t *testing.T) {
+	index := steam.NewAppIndex(testApps)
+
+	for _, test := range []struct {
+		name  string
+		match steam.Match
+		ids   []uint64
+	}{
+		{"Elden Ring", steam.MatchExact, []uint64{1245621}},
+		{"elden ring", steam.MatchExact, nil},
+		{"elden ring", steam.MatchCaseInsensitive, []uint64{1245620, 1245621}},
+		{"the witcher 3 wild hunt", steam.MatchCaseInsensitive, nil},
+		{"the witcher 3 wild hunt", steam.MatchFuzzy, []uint64{292030, 499450}},
+		{"Red Dead Redemtion 2", steam.MatchFuzzy, []uint64{1174180}},
+		{"Half-Life", steam.MatchFuzzy, nil},
+	} {
+		apps, err := index.FindName(test.name, test.match)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(apps) != len(test.ids) {
+			t.Errorf("FindName(%q, %d) = %v (expected IDs %v)", test.name, test.match, apps, test.ids)
+			continue
+		}
+		for i, app := range apps {
+			if app.AppID != test.ids[i] {
+				t.Errorf("FindName(%q, %d) = %v (expected IDs %v)", test.name, test.match, apps, test.ids)
+				break
+			}
+		}
+	}
+}
@@ -1,17 +1,20 @@
 package steam
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"sync"
 
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/helpers"
 	"github.com/sirupsen/logrus"
 )
 
 const Name = "steam"
 const gameListURL = "https://api.steampowered.com/ISteamApps/GetAppList/v2/"
-const baseGameHeaderURL = "https://cdn.cloudflare.steamstatic.com/steam/apps/%d/header.jpg"
 
 var errGameIDNotFound = errors.New("game ID not found")
 
@@ -24,17 +27,55 @@ type SteamAppList struct {
 	Apps []SteamApp `json:"apps"`
 }
 
-func (appList SteamAppList) FindID(id string) (result SteamApp, err error) {
-	for _, game := range appList.Apps {
-		uintGameID, err := strconv.ParseUint(id, 10, 64)
-		if err != nil {
-			return result, fmt.Errorf("error parsing game ID: %s", err)
+// indexedSteamAppList finds apps in a cache holding a record per app, keyed
+// by ID, so the app list doesn't need to be read into memory. Searching by
+// name reads all the records into an index the first time.
+type indexedSteamAppList struct {
+	cache models.BatchCache
+
+	namesOnce sync.Once
+	names     *AppIndex
+	namesErr  error
+}
+
+func (appList *indexedSteamAppList) FindID(id string) (result SteamApp, err error) {
+	uintGameID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return result, errGameIDNotFound
+	}
+
+	name, err := appList.cache.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrCacheKeyDontExist) {
+			return result, errGameIDNotFound
 		}
-		if game.AppID == uintGameID {
-			return game, nil
+		return result, err
+	}
+
+	return SteamApp{AppID: uintGameID, Name: name}, nil
+}
+
+func (appList *indexedSteamAppList) loadNames() {
+	var apps []SteamApp
+	err := appList.cache.ForEach(func(id, name string) error {
+		if appID, err := strconv.ParseUint(id, 10, 64); err == nil {
+			apps = append(apps, SteamApp{AppID: appID, Name: name})
 		}
+		return nil
+	})
+	if err != nil {
+		appList.namesErr = fmt.Errorf("error reading steam app records: %s", err)
+		return
+	}
+	appList.names = NewAppIndex(apps)
+}
+
+func (appList *indexedSteamAppList) FindName(name string, match Match) ([]SteamApp, error) {
+	appList.namesOnce.Do(appList.loadNames)
+	if appList.namesErr != nil {
+		return nil, appList.namesErr
 	}
-	return result, errGameIDNotFound
+	return appList.names.FindName(name, match)
 }
 
 type SteamAppListResponse struct {
@@ -44,17 +85,33 @@ type SteamAppListResponse struct {
 type SteamProvider struct {
 	logger *logrus.Entry
 	cache  models.Cache
+
+	appsOnce sync.Once
+	apps     AppFinder
+	appsErr  error
+}
+
+// Apps returns the Steam app list, to find apps by ID or name. It's only
+// retrieved once per run.
+func (p *SteamProvider) Apps(ctx context.Context) (AppFinder, error) {
+	p.appsOnce.Do(func() {
+		p.apps, p.appsErr = getSteamAppList(ctx, p.logger, p.cache)
+	})
+	return p.apps, p.appsErr
 }
 
-func (p *SteamProvider) FindGames(options models.ProviderOptions) ([]*models.Game, error) {
+func (p *SteamProvider) FindGames(ctx context.Context, options models.ProviderOptions) ([]*models.Game, error) {
 	basePath, err := getBasePathForOS()
 	if err != nil {
 		return nil, fmt.Errorf("error getting steam's base path: %s", err)
 	}
 
 	var localGames []*models.Game
-	c := make(chan SteamAppList)
-	go getSteamAppList(p.logger, p.cache, c)
+	c := make(chan error, 1)
+	go func() {
+		_, err := p.Apps(ctx)
+		c <- err
+	}()
 
 	users, err := guessUsers(basePath)
 	if err != nil {
@@ -63,11 +120,10 @@ func (p *SteamProvider) FindGames(options models.ProviderOptions) ([]*models.Gam
 
 	p.logger.Debugf("Found %d users", len(users))
 
-	steamApps := <-c
-
-	if len(steamApps.Apps) == 0 {
-		return nil, fmt.Errorf("coulnd't get steam app list")
+	if err := <-c; err != nil {
+		return nil, fmt.Errorf("coulnd't get steam app list: %s", err)
 	}
+	steamApps, _ := p.Apps(ctx)
 
 	for _, userID := range users {
 		userGames, err := getGamesFromUser(basePath, userID)
@@ -78,13 +134,12 @@ func (p *SteamProvider) FindGames(options models.ProviderOptions) ([]*models.Gam
 		for _, userGameID := range userGames {
 			steamGame, err := steamApps.FindID(userGameID)
 			if err != nil {
-				p.logger.Errorf("Steam game ID not found: %s", userGameID)
+				// Imported without a name so it's reported as unresolved
+				p.logger.Debugf("Steam game ID not found: %s", userGameID)
 			}
 			p.logger.WithField("userID", userID).Debugf("Found game: %s", steamGame.Name)
 			userGame := models.NewGame(userGameID, steamGame.Name, "PC", Name)
 
-			userGame.CoverURL = fmt.Sprintf(baseGameHeaderURL, steamGame.AppID)
-
 			if err := getScreenshotsForGame(basePath, userID, &userGame); err != nil {
 				p.logger.Errorf("error getting screenshots: %s", err)
 			}
@@ -94,6 +149,26 @@ func (p *SteamProvider) FindGames(options models.ProviderOptions) ([]*models.Gam
 	return localGames, nil
 }
 
+// WarmCache downloads the Steam app list if it's not cached already.
+func (p *SteamProvider) WarmCache(ctx context.Context) error {
+	if _, err := p.Apps(ctx); err != nil {
+		return fmt.Errorf("coulnd't get steam app list: %s", err)
+	}
+	return nil
+}
+
+func (p *SteamProvider) Info() models.ProviderInfo {
+	return models.ProviderInfo{}
+}
+
+func (p *SteamProvider) Detect(options models.ProviderOptions) (string, error) {
+	basePath, err := getBasePathForOS()
+	if err != nil {
+		return "", err
+	}
+	return helpers.FirstExistingPath(filepath.Join(basePath, "userdata"))
+}
+
 func NewSteamProvider(logger *logrus.Logger, cache models.Cache) models.Provider {
 	return &SteamProvider{
 		cache:  cache,
@@ -0,0 +1,54 @@
+package steam_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/fmartingr/games-screenshot-manager/internal/models"
+	"github.com/fmartingr/games-screenshot-manager/pkg/cache"
+	"github.com/fmartingr/games-screenshot-manager/pkg/providers/steam"
+	"github.com/sirupsen/logrus"
+)
+
+// TestProviderCachedAppList
+// Tests that apps are found in the app list cached in its compact form, in
+// the JSON form stored by older versions, and as records
+func TestProviderCachedAppList(t *testing.T) {
+	records, err := cache.NewBoltCacheWithPath(logrus.New(), filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer records.Close()
+	if err := records.Namespace("apps").(models.BatchCache).PutBatch(map[string]string{"1245620": "ELDEN RING", "570": "Dota 2"}, models.CacheMetadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := records.Put("steam-applist-index", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	compact := cache.NewMemoryCache(logrus.New())
+	compact.Put("steam-applist", "570\tDota 2\n1245620\tELDEN RING\n")
+	legacy := cache.NewMemoryCache(logrus.New())
+	legacy.Put("steam-applist", `{"applist":{"apps":[{"appid":570,"name":"Dota 2"},{"appid":1245620,"name":"ELDEN RING"}]}}`)
+
+	for name, c := range map[string]models.Cache{
+		"compact": compact,
+		"json":    legacy,
+		"records": records,
+	} {
+		provider := steam.NewSteamProvider(logrus.New(), c).(*steam.SteamProvider)
+		apps, err := provider.Apps(context.Background())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+			continue
+		}
+
+		if app, err := apps.FindID("1245620"); err != nil || app.Name != "ELDEN RING" {
+			t.Errorf("%s: FindID(1245620) = %v, %v", name, app, err)
+		}
+		if found, err := apps.FindName("Dota 2", steam.MatchExact); err != nil || len(found) != 1 || found[0].AppID != 570 {
+			t.Errorf("%s: FindName(Dota 2) = %v, %v", name, found, err)
+		}
+	}
+}
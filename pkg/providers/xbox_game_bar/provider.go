@@ -1,6 +1,7 @@
 package xbox_game_bar
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -29,7 +30,7 @@ type XboxGameBarProvider struct {
 	logger *logrus.Entry
 }
 
-func (p *XboxGameBarProvider) FindGames(options models.ProviderOptions) ([]*models.Game, error) {
+func (p *XboxGameBarProvider) FindGames(ctx context.Context, options models.ProviderOptions) ([]*models.Game, error) {
 	var userGames []*models.Game
 
 	path := helpers.ExpandUser(options.InputPath)
@@ -111,6 +112,17 @@ func (p *XboxGameBarProvider) FindGames(options models.ProviderOptions) ([]*mode
 	return userGames, nil
 }
 
+func (p *XboxGameBarProvider) Info() models.ProviderInfo {
+	return models.ProviderInfo{
+		InputPath:         "Captures directory of the Xbox Game Bar",
+		InputPathRequired: true,
+	}
+}
+
+func (p *XboxGameBarProvider) Detect(options models.ProviderOptions) (string, error) {
+	return helpers.DetectInputPath(options)
+}
+
 func NewXboxGameGarProvider(logger *logrus.Logger, cache models.Cache) models.Provider {
 	return &XboxGameBarProvider{
 		logger: logger.WithField("from", "provider."+Name),
@@ -2,6 +2,7 @@ package registry
 
 import (
 	"errors"
+	"sort"
 
 	"github.com/fmartingr/games-screenshot-manager/internal/models"
 	"github.com/sirupsen/logrus"
@@ -23,7 +24,8 @@ func (r *ProviderRegistry) Register(name string, providerFactory models.Provider
 		return ErrProviderAlreadyRegistered
 	}
 
-	provider := providerFactory(r.logger.Logger, r.cache)
+	// Each provider gets its own namespace so cache keys don't collide
+	provider := providerFactory(r.logger.Logger, r.cache.Namespace(name))
 	r.providers[name] = &provider
 
 	return nil
@@ -37,6 +39,16 @@ func (r *ProviderRegistry) Get(providerName string) (models.Provider, error) {
 	return *provider, nil
 }
 
+// Names returns the names of the registered providers, sorted.
+func (r *ProviderRegistry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func NewProviderRegistry(logger *logrus.Logger, cache models.Cache) *ProviderRegistry {
 	return &ProviderRegistry{
 		logger:    logger.WithField("from", "registry"),